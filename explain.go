@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/stukennedy/kyotee/internal/events"
+	"github.com/stukennedy/kyotee/internal/server"
+)
+
+// runRemoteExplain implements `kyotee explain <task_id>`: a plain-English
+// account of what the engine decided for a task and why, built from its
+// persisted event trail. It is read-only and makes no model calls.
+func runRemoteExplain(baseURL, taskID string, stdout io.Writer) error {
+	client := newRemoteClient(baseURL)
+	var info server.TaskInfo
+	if err := client.getJSON("/v1/tasks/"+taskID, &info); err != nil {
+		return err
+	}
+
+	resp, err := client.http.Get(client.baseURL + "/v1/tasks/" + taskID + "/events?follow=false")
+	if err != nil {
+		return errNoEngine(client.baseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return apiErrFrom(resp)
+	}
+	var evs []events.Event
+	if err := scanEvents(resp.Body, func(ev events.Event) { evs = append(evs, ev) }); err != nil {
+		return fmt.Errorf("event stream interrupted: %w", err)
+	}
+	_, err = io.WriteString(stdout, explainTrail(info, evs))
+	return err
+}
+
+// explainTrail narrates a task's events in order: how it was classified and
+// routed, the thinking and tool decisions, the debate outcome, budget
+// pressure, and how it ended. Events are sorted by Seq, so a replay merged
+// from the persisted log and the live bus reads in the order it happened.
+func explainTrail(info server.TaskInfo, evs []events.Event) string {
+	evs = append([]events.Event(nil), evs...)
+	sort.SliceStable(evs, func(i, j int) bool { return evs[i].Seq < evs[j].Seq })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task %s (%s)\n", info.TaskID, info.Status)
+	fmt.Fprintf(&b, "  %q\n\n", oneLine(info.Original, 100))
+
+	var tools, cached, toolErrs int
+	var rebuttals, turns int
+	for _, ev := range evs {
+		p := ev.Payload
+		switch ev.Kind {
+		case events.KindTaskClassified:
+			fmt.Fprintf(&b, "- Classified as %v/%v, tool need %v (confidence %.2f).", p["domain"], p["complexity"], p["tool_need"], num(p["confidence"]))
+			if r, _ := p["rationale"].(string); r != "" {
+				fmt.Fprintf(&b, " %s", oneLine(r, 160))
+			}
+			b.WriteString("\n")
+		case events.KindTaskRouted:
+			fmt.Fprintf(&b, "- Routed to %v with %v thinking and a $%.2f budget", p["strategy"], p["thinking"], num(p["limit_usd"]))
+			if stages, ok := p["pipeline"].([]any); ok && len(stages) > 0 {
+				names := make([]string, len(stages))
+				for i, s := range stages {
+					names[i] = fmt.Sprint(s)
+				}
+				fmt.Fprintf(&b, "; pipeline %s", strings.Join(names, " → "))
+			}
+			b.WriteString(".\n")
+		case events.KindThinkingMode:
+			fmt.Fprintf(&b, "- Thinking %v: %v.\n", p["mode"], p["reason"])
+		case events.KindThinkingToolChk:
+			fmt.Fprintf(&b, "- Tool pre-pass: %v %v.\n", p["verdict"], p["tools"])
+		case events.KindToolCall:
+			tools++
+		case events.KindToolResult:
+			if c, _ := p["cached"].(bool); c {
+				cached++
+			}
+			if e, _ := p["is_error"].(bool); e {
+				toolErrs++
+			}
+		case events.KindBrainTurn:
+			turns++
+		case events.KindCouncilRebuttal:
+			rebuttals++
+		case events.KindCouncilConsensus:
+			if reached, _ := p["reached"].(bool); reached {
+				fmt.Fprintf(&b, "- Council reached consensus by %v after %d rebuttals.\n", p["method"], rebuttals)
+			} else {
+				fmt.Fprintf(&b, "- Council had no consensus by %v after %d rebuttals.\n", p["method"], rebuttals)
+			}
+		case events.KindBudgetWarn:
+			if r, _ := p["reason"].(string); r != "" {
+				fmt.Fprintf(&b, "- Budget: %s.\n", r)
+			} else {
+				fmt.Fprintf(&b, "- Budget: %.0f%% spent ($%.4f of $%.2f).\n", num(p["pct"])*100, num(p["spent_usd"]), num(p["limit_usd"]))
+			}
+		case events.KindConfigChanged:
+			fmt.Fprintf(&b, "- %v.\n", p["message"])
+		case events.KindError:
+			fmt.Fprintf(&b, "- Error: %v.\n", p["message"])
+		case events.KindTaskFinal:
+			if tools > 0 {
+				fmt.Fprintf(&b, "- Made %d tool calls (%d served from cache, %d failed).\n", tools, cached, toolErrs)
+				tools, cached, toolErrs = 0, 0, 0
+			}
+			if turns > 0 {
+				fmt.Fprintf(&b, "- Two-brain exchange: %d turns.\n", turns)
+				turns = 0
+			}
+			fmt.Fprintf(&b, "- Finished (%v): $%.4f, %d tokens.\n", p["reason"], num(p["total_cost_usd"]), int(num(p["total_tokens"])))
+		}
+	}
+	// A run that stopped without task.final (aborted, timed out, failed)
+	// still reports what it did.
+	if tools > 0 {
+		fmt.Fprintf(&b, "- Made %d tool calls (%d served from cache, %d failed).\n", tools, cached, toolErrs)
+	}
+	if turns > 0 {
+		fmt.Fprintf(&b, "- Two-brain exchange: %d turns.\n", turns)
+	}
+	if len(evs) == 0 {
+		b.WriteString("- No events recorded.\n")
+	}
+	return b.String()
+}
+
+// oneLine collapses whitespace and caps s at n runes.
+func oneLine(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
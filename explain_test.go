@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stukennedy/kyotee/internal/events"
+	"github.com/stukennedy/kyotee/internal/receptionist"
+	"github.com/stukennedy/kyotee/internal/server"
+)
+
+// The trail is a pure function of the events: same input, same text, in Seq
+// order regardless of arrival order, with tool calls summarised not listed.
+func TestExplainTrailDeterministic(t *testing.T) {
+	info := server.TaskInfo{TaskID: "t1", Status: "completed", Original: "who is the\ncurrent UK pm?"}
+	evs := []events.Event{
+		{Seq: 2, Kind: events.KindTaskRouted, Payload: map[string]any{
+			"strategy": "solo", "thinking": "slow", "limit_usd": 0.5,
+			"pipeline": []any{"thinking", "solo"}}},
+		{Seq: 1, Kind: events.KindTaskClassified, Payload: map[string]any{
+			"domain": "factual", "complexity": "simple", "tool_need": "required",
+			"confidence": 0.9, "rationale": "present-state fact"}},
+		{Seq: 3, Kind: events.KindThinkingMode, Payload: map[string]any{"mode": "slow", "reason": "tool_need=required"}},
+		{Seq: 4, Kind: events.KindThinkingToolChk, Payload: map[string]any{"verdict": "required", "tools": []any{"web_search"}}},
+		{Seq: 5, Kind: events.KindToolCall, Payload: map[string]any{"name": "web_search"}},
+		{Seq: 6, Kind: events.KindToolResult, Payload: map[string]any{"name": "web_search", "cached": true}},
+		{Seq: 7, Kind: events.KindBudgetWarn, Payload: map[string]any{"pct": 0.5, "spent_usd": 0.25, "limit_usd": 0.5}},
+		{Seq: 8, Kind: events.KindTaskFinal, Payload: map[string]any{"reason": "completed", "total_cost_usd": 0.3, "total_tokens": 1200.0}},
+	}
+
+	got := explainTrail(info, evs)
+	want := `Task t1 (completed)
+  "who is the current UK pm?"
+
+- Classified as factual/simple, tool need required (confidence 0.90). present-state fact
+- Routed to solo with slow thinking and a $0.50 budget; pipeline thinking → solo.
+- Thinking slow: tool_need=required.
+- Tool pre-pass: required [web_search].
+- Budget: 50% spent ($0.2500 of $0.50).
+- Made 1 tool calls (1 served from cache, 0 failed).
+- Finished (completed): $0.3000, 1200 tokens.
+`
+	if got != want {
+		t.Fatalf("trail =\n%s\nwant\n%s", got, want)
+	}
+	if again := explainTrail(info, evs); again != got {
+		t.Fatal("explainTrail is not deterministic")
+	}
+	if evs[0].Seq != 2 {
+		t.Fatal("explainTrail must not reorder the caller's slice")
+	}
+}
+
+// A run that ends without task.final (aborted, timed out) still reports its
+// two-brain exchange.
+func TestExplainTrailUnfinishedTwoBrain(t *testing.T) {
+	info := server.TaskInfo{TaskID: "t2", Original: "design a cache", Status: "aborted"}
+	evs := []events.Event{
+		{Seq: 1, Kind: events.KindBrainTurn, Payload: map[string]any{"brain": "divergent"}},
+		{Seq: 2, Kind: events.KindBrainTurn, Payload: map[string]any{"brain": "convergent"}},
+		{Seq: 3, Kind: events.KindBrainTurn, Payload: map[string]any{"brain": "divergent"}},
+	}
+	got := explainTrail(info, evs)
+	if !strings.Contains(got, "- Two-brain exchange: 3 turns.\n") {
+		t.Fatalf("unfinished run lost its two-brain turns:\n%s", got)
+	}
+}
+
+// End to end: explain fetches a finished task's trail over HTTP without
+// following the (already closed) live stream.
+func TestRemoteExplain(t *testing.T) {
+	_, srv := mockEngineServer(t)
+	var out, errb bytes.Buffer
+	if err := runRemoteAsk(srv.URL, "hello", "", receptionist.Overrides{}, true, true, &out, &errb); err != nil {
+		t.Fatal(err)
+	}
+	var res askResult
+	if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+		t.Fatalf("bad --json output: %v\n%s", err, out.String())
+	}
+	taskID := res.TaskID
+
+	var stdout bytes.Buffer
+	if err := runRemoteExplain(srv.URL, taskID, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Task " + taskID + " (completed)", "- Routed to solo", "- Finished (completed)"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("explain output missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if err := runRemoteExplain(srv.URL, "nope", &stdout); err == nil {
+		t.Fatal("unknown task should fail")
+	}
+}
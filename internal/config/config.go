@@ -5,11 +5,15 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -27,6 +31,8 @@ type Config struct {
 	Council      Council      `yaml:"council"`
 	Tools        []Tool       `yaml:"tools"`
 	Embedder     Embedder     `yaml:"embedder"`
+	Policies     Policies     `yaml:"policies"`
+	TUI          TUI          `yaml:"tui"`
 }
 
 // Defaults are global fallbacks (spec 07 §2).
@@ -35,6 +41,11 @@ type Defaults struct {
 	ReasoningEffortFast string  `yaml:"reasoning_effort_fast"` // effort in fast mode
 	ReasoningEffortSlow string  `yaml:"reasoning_effort_slow"` // effort in slow mode
 	ToolCallCap         int     `yaml:"tool_call_cap"`         // max tool calls per solver loop
+	ToolRepeatLimit     int     `yaml:"tool_repeat_limit"`     // identical tool-call rounds before the loop is nudged, then failed
+	ToolTimeoutSeconds  int     `yaml:"tool_timeout_seconds"`  // wall-clock cap on a single tool execution
+	MaxRunSeconds       int     `yaml:"max_run_seconds"`       // wall-clock cap on one task run; 0 = unlimited
+	MaxTaskTokens       int     `yaml:"max_task_tokens"`       // input+output tokens per task across all calls; 0 = unlimited
+	VerdictRetries      int     `yaml:"verdict_retries"`       // re-prompts after an unparseable classifier/gate/pre-pass/judge verdict; < 0 = none
 }
 
 // Provider declares one model endpoint. Vendor selects the adapter:
@@ -63,6 +74,7 @@ type Receptionist struct {
 	Model            string    `yaml:"model"`              // cheap classifier
 	BudgetDefaultUSD float64   `yaml:"budget_default_usd"` // overrides defaults.budget_usd when set
 	WarnThresholds   []float64 `yaml:"warn_thresholds"`    // sorted, each in (0,1)
+	OnNoMatch        string    `yaml:"on_no_match"`        // warn (default: solo on the classifier model) | reject
 	Routes           []Route   `yaml:"routes"`
 }
 
@@ -132,8 +144,40 @@ type Consensus struct {
 // Tool declares one registry entry (spec 07 §2 tools block).
 type Tool struct {
 	Name string `yaml:"name"`
-	Kind string `yaml:"kind"` // web_search | file_read
-	Root string `yaml:"root"` // file_read sandbox root
+	Kind string `yaml:"kind"` // web_search | file_read | file_edit | file_search
+	Root string `yaml:"root"` // file_read/file_edit/file_search sandbox root
+}
+
+// Policies are safety switches that apply to every task.
+type Policies struct {
+	// RedactSecrets masks credentials (API keys, tokens, passwords, private
+	// keys) in tool output before models or logs see it. Default true.
+	RedactSecrets *bool `yaml:"redact_secrets"`
+	// ForbidNetwork blocks tools that reach the network (web_search): calls
+	// return a policy error instead of running. For air-gapped or
+	// reproducible runs. Default false.
+	ForbidNetwork bool `yaml:"forbid_network"`
+}
+
+// TUI holds terminal-UI display settings. The engine ignores them; the TUI
+// reads them from the local config at launch.
+type TUI struct {
+	Theme    string `yaml:"theme"`     // cyberpunk (default) | light | mono | solarized
+	LogLines int    `yaml:"log_lines"` // event-log lines kept in memory per task; default 500
+	LogView  int    `yaml:"log_view"`  // event-log lines shown; default 6
+}
+
+// logSizes returns the effective log retention and view height, with 0
+// meaning the default for either.
+func (t TUI) logSizes() (lines, view int) {
+	lines, view = t.LogLines, t.LogView
+	if lines == 0 {
+		lines = 500
+	}
+	if view == 0 {
+		view = 6
+	}
+	return lines, view
 }
 
 type Embedder struct {
@@ -168,6 +212,18 @@ func (c *Config) ApplyDefaults() {
 	if c.Defaults.ToolCallCap == 0 {
 		c.Defaults.ToolCallCap = 4
 	}
+	if c.Defaults.ToolRepeatLimit == 0 {
+		c.Defaults.ToolRepeatLimit = 3
+	}
+	if c.Defaults.ToolTimeoutSeconds == 0 {
+		c.Defaults.ToolTimeoutSeconds = 120
+	}
+	if c.Defaults.VerdictRetries == 0 {
+		c.Defaults.VerdictRetries = 2
+	}
+	if c.Receptionist.OnNoMatch == "" {
+		c.Receptionist.OnNoMatch = "warn"
+	}
 	if len(c.Receptionist.WarnThresholds) == 0 {
 		c.Receptionist.WarnThresholds = []float64{0.5, 0.8, 0.95}
 	}
@@ -218,6 +274,14 @@ func (c *Config) ApplyDefaults() {
 	if len(c.Tools) == 0 {
 		c.Tools = []Tool{{Name: "web_search", Kind: "web_search"}}
 	}
+	if c.Policies.RedactSecrets == nil {
+		on := true
+		c.Policies.RedactSecrets = &on
+	}
+	if c.TUI.Theme == "" {
+		c.TUI.Theme = "cyberpunk"
+	}
+	c.TUI.LogLines, c.TUI.LogView = c.TUI.logSizes()
 	for i := range c.Providers {
 		if c.Providers[i].Model == "" {
 			c.Providers[i].Model = c.Providers[i].Name
@@ -228,6 +292,37 @@ func (c *Config) ApplyDefaults() {
 	}
 }
 
+// Fingerprint is a short content hash of the execution-affecting config,
+// recorded on each task so resume can tell when it has changed underneath
+// it. Server placement (listen, state_dir) and the tui section are left out:
+// they never change what a run does.
+func (c *Config) Fingerprint() string {
+	data, err := yaml.Marshal(struct {
+		Defaults     Defaults
+		Providers    []Provider
+		Receptionist Receptionist
+		Thinking     Thinking
+		TwoBrain     TwoBrain
+		Council      Council
+		Tools        []Tool
+		Embedder     Embedder
+		Policies     Policies
+	}{c.Defaults, c.Providers, c.Receptionist, c.Thinking, c.TwoBrain, c.Council, c.Tools, c.Embedder, c.Policies})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// MaxRunDuration is the wall-clock cap on one task run (0 = unlimited).
+func (c *Config) MaxRunDuration() time.Duration {
+	if c.Defaults.MaxRunSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.Defaults.MaxRunSeconds) * time.Second
+}
+
 // BudgetDefaultUSD resolves the effective global per-task ceiling.
 func (c *Config) BudgetDefaultUSD() float64 {
 	if c.Receptionist.BudgetDefaultUSD > 0 {
@@ -242,6 +337,12 @@ func (c *Config) Validate() error {
 	if c.Version != 1 {
 		return fmt.Errorf("version must be 1, got %d", c.Version)
 	}
+	if c.Defaults.MaxRunSeconds < 0 {
+		return fmt.Errorf("defaults.max_run_seconds must be >= 0 (0 = unlimited), got %d", c.Defaults.MaxRunSeconds)
+	}
+	if c.Defaults.MaxTaskTokens < 0 {
+		return fmt.Errorf("defaults.max_task_tokens must be >= 0 (0 = unlimited), got %d", c.Defaults.MaxTaskTokens)
+	}
 
 	names := map[string]string{} // name → vendor
 	for _, p := range c.Providers {
@@ -290,6 +391,11 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	switch c.Receptionist.OnNoMatch {
+	case "warn", "reject":
+	default:
+		return fmt.Errorf("receptionist.on_no_match must be warn|reject, got %q", c.Receptionist.OnNoMatch)
+	}
 	if !sort.Float64sAreSorted(c.Receptionist.WarnThresholds) {
 		return fmt.Errorf("receptionist.warn_thresholds must be sorted ascending")
 	}
@@ -302,6 +408,25 @@ func (c *Config) Validate() error {
 	if c.TwoBrain.Rounds < 1 || c.TwoBrain.Rounds > TwoBrainRoundsMax {
 		return fmt.Errorf("twobrain.rounds must be in [1,%d], got %d", TwoBrainRoundsMax, c.TwoBrain.Rounds)
 	}
+	// Persona prompt files are otherwise read only when a twobrain task
+	// assembles — after a paid classification call. Report every missing
+	// one at once so a single edit fixes them all.
+	var missing []string
+	for _, p := range []struct{ key, path string }{
+		{"divergent", c.TwoBrain.Prompts.Divergent},
+		{"convergent", c.TwoBrain.Prompts.Convergent},
+		{"referee", c.TwoBrain.Prompts.Referee},
+	} {
+		if p.path == "" {
+			continue
+		}
+		if fi, err := os.Stat(p.path); err != nil || fi.IsDir() {
+			missing = append(missing, p.key+"="+p.path)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("twobrain.prompts: missing prompt files: %s", strings.Join(missing, ", "))
+	}
 	if c.Council.Rounds < 1 || c.Council.Rounds > CouncilRoundsHardCap {
 		return fmt.Errorf("council.rounds must be in [1,%d], got %d", CouncilRoundsHardCap, c.Council.Rounds)
 	}
@@ -325,12 +450,12 @@ func (c *Config) Validate() error {
 	for _, t := range c.Tools {
 		switch t.Kind {
 		case "web_search":
-		case "file_read":
+		case "file_read", "file_edit", "file_search":
 			if t.Root == "" {
-				return fmt.Errorf("tool %q: kind file_read requires root", t.Name)
+				return fmt.Errorf("tool %q: kind %s requires root", t.Name, t.Kind)
 			}
 		default:
-			return fmt.Errorf("tool %q: unknown kind %q (web_search|file_read)", t.Name, t.Kind)
+			return fmt.Errorf("tool %q: unknown kind %q (web_search|file_read|file_edit|file_search)", t.Name, t.Kind)
 		}
 	}
 
@@ -362,6 +487,18 @@ func (c *Config) Validate() error {
 			}
 		}
 	}
+
+	switch c.TUI.Theme {
+	case "", "cyberpunk", "light", "mono", "solarized":
+	default:
+		return fmt.Errorf("tui.theme: unknown theme %q (cyberpunk|light|mono|solarized)", c.TUI.Theme)
+	}
+	if c.TUI.LogView < 0 || c.TUI.LogLines < 0 {
+		return fmt.Errorf("tui.log_lines and tui.log_view must not be negative")
+	}
+	if lines, view := c.TUI.logSizes(); lines < view {
+		return fmt.Errorf("tui.log_lines (%d) must be >= tui.log_view (%d)", lines, view)
+	}
 	return nil
 }
 
@@ -425,11 +562,16 @@ func DefaultPath() string {
 	return filepath.Join(home, ".kyotee", "config.yaml")
 }
 
+// ProjectPath is the project-local config, relative to the working
+// directory, layered over the global one.
+var ProjectPath = filepath.Join(".kyotee", "config.yaml")
+
 // Load reads, defaults, and validates a config file. A missing file yields
-// the built-in default config.
+// the built-in default config. With no explicit path, the global file is
+// overlaid with ProjectPath when present (see Layer).
 func Load(path string) (*Config, error) {
 	if path == "" {
-		path = DefaultPath()
+		return Layer(DefaultPath(), ProjectPath)
 	}
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
@@ -441,6 +583,45 @@ func Load(path string) (*Config, error) {
 	return Parse(data)
 }
 
+// Layer decodes the global config (built-in default when missing), then
+// decodes the project file over it: any key the project file sets wins,
+// everything else is inherited. Lists are replaced wholesale, not merged —
+// a project that declares providers declares all of them. The result is
+// defaulted and validated as one config.
+func Layer(global, project string) (*Config, error) {
+	base, err := os.ReadFile(global)
+	haveBase := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	overlay, err := os.ReadFile(project)
+	haveOverlay := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	c := Default()
+	switch {
+	case !haveBase && !haveOverlay:
+		return c, nil
+	case haveBase:
+		c = &Config{}
+		if err := yaml.Unmarshal(base, c); err != nil {
+			return nil, fmt.Errorf("parse config: %w", err)
+		}
+	}
+	if haveOverlay {
+		if err := yaml.Unmarshal(overlay, c); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", project, err)
+		}
+	}
+	c.ApplyDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 // Parse decodes, defaults, and validates raw YAML.
 func Parse(data []byte) (*Config, error) {
 	var c Config
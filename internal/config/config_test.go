@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -38,6 +40,9 @@ func TestValidConfigLoads(t *testing.T) {
 	if cfg.Defaults.ToolCallCap != 4 || cfg.TwoBrain.DivTemp != 1.0 || cfg.TwoBrain.ConvTemp != 0.3 {
 		t.Fatalf("defaults not applied: %+v %+v", cfg.Defaults, cfg.TwoBrain)
 	}
+	if cfg.MaxRunDuration() != 0 {
+		t.Fatalf("unset max_run_seconds should mean unlimited, got %v", cfg.MaxRunDuration())
+	}
 }
 
 // Each validation rule rejects a crafted invalid config with a specific
@@ -145,12 +150,47 @@ version: 1
 providers: [{name: a, vendor: mock}]
 receptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}
 tools: [{name: rf, kind: file_read}]
+`, "requires root"},
+		{"file_edit tool without root", `
+version: 1
+providers: [{name: a, vendor: mock}]
+receptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}
+tools: [{name: ef, kind: file_edit}]
 `, "requires root"},
 		{"unknown vendor", `
 version: 1
 providers: [{name: a, vendor: quantum}]
 receptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}
 `, "unknown vendor"},
+		{"unknown on_no_match policy", `
+version: 1
+providers: [{name: a, vendor: mock}]
+receptionist: {model: a, on_no_match: block, routes: [{strategy: solo, models: {primary: a}}]}
+`, "on_no_match"},
+		{"unknown tui theme", `
+version: 1
+providers: [{name: a, vendor: mock}]
+receptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}
+tui: {theme: neon}
+`, "tui.theme"},
+		{"tui log view larger than retention", `
+version: 1
+providers: [{name: a, vendor: mock}]
+receptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}
+tui: {log_lines: 4, log_view: 10}
+`, "tui.log_lines"},
+		{"tui log retention below the default view", `
+version: 1
+providers: [{name: a, vendor: mock}]
+receptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}
+tui: {log_lines: 3}
+`, "tui.log_view (6)"},
+		{"negative tui log size", `
+version: 1
+providers: [{name: a, vendor: mock}]
+receptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}
+tui: {log_view: -1}
+`, "must not be negative"},
 	}
 
 	for _, tc := range cases {
@@ -197,3 +237,74 @@ func TestDefaultConfigIsValid(t *testing.T) {
 		t.Fatalf("built-in default config invalid: %v", err)
 	}
 }
+
+// Display-only settings must not trip the config-changed warning on resume.
+func TestFingerprintIgnoresTUI(t *testing.T) {
+	base := Default()
+	c := Default()
+	c.TUI.Theme, c.TUI.LogView = "mono", 12
+	if c.Fingerprint() != base.Fingerprint() {
+		t.Fatal("tui change altered the fingerprint")
+	}
+	c.Defaults.ToolCallCap++
+	if c.Fingerprint() == base.Fingerprint() {
+		t.Fatal("defaults change left the fingerprint unchanged")
+	}
+}
+
+// A project-local config overrides the keys it sets and inherits the rest
+// from the global file.
+func TestProjectConfigOverridesGlobal(t *testing.T) {
+	dir := t.TempDir()
+	global := filepath.Join(dir, "global.yaml")
+	project := filepath.Join(dir, "project.yaml")
+	if err := os.WriteFile(global, []byte(validYAML()+"defaults: {tool_call_cap: 6, budget_usd: 2}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(project, []byte("defaults: {tool_call_cap: 9}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Layer(global, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Defaults.ToolCallCap != 9 {
+		t.Fatalf("project value lost: tool_call_cap=%d", cfg.Defaults.ToolCallCap)
+	}
+	if cfg.Defaults.BudgetUSD != 2 || cfg.Receptionist.Model != "haiku" {
+		t.Fatalf("global values lost: %+v %q", cfg.Defaults, cfg.Receptionist.Model)
+	}
+
+	// No project file: the global config alone.
+	cfg, err = Layer(global, filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Defaults.ToolCallCap != 6 {
+		t.Fatalf("tool_call_cap=%d, want global 6", cfg.Defaults.ToolCallCap)
+	}
+}
+
+// Missing twobrain prompt files are rejected up front, all named in one error.
+func TestMissingTwoBrainPromptsRejected(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "div.md")
+	if err := os.WriteFile(present, []byte("diverge"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	yml := validYAML() + "twobrain:\n  prompts:\n    divergent: " + present +
+		"\n    convergent: " + filepath.Join(dir, "conv.md") +
+		"\n    referee: " + filepath.Join(dir, "ref.md") + "\n"
+	_, err := Parse([]byte(yml))
+	if err == nil {
+		t.Fatal("missing prompt files should fail validation")
+	}
+	for _, want := range []string{"convergent=", "referee=", "conv.md", "ref.md"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q should name %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "div.md") {
+		t.Fatalf("error %q names a prompt file that exists", err)
+	}
+}
@@ -8,8 +8,11 @@ func Default() *Config {
 	c := &Config{
 		Version: 1,
 		Defaults: Defaults{
-			BudgetUSD:   0.50,
-			ToolCallCap: 4,
+			BudgetUSD:          0.50,
+			ToolCallCap:        4,
+			ToolRepeatLimit:    3,
+			ToolTimeoutSeconds: 120,
+			VerdictRetries:     2,
 		},
 		Providers: []Provider{
 			{
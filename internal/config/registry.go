@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/stukennedy/kyotee/internal/provider"
 	"github.com/stukennedy/kyotee/internal/thinking"
@@ -15,7 +16,8 @@ const googleOpenAIBase = "https://generativelanguage.googleapis.com/v1beta/opena
 // providers. Vendor selects the adapter: anthropic → Messages API;
 // openai/google/local → OpenAI-compatible chat completions; mock → Fake.
 // Providers with unset API keys are still registered — the error surfaces at
-// call time (config load already warns loudly).
+// call time, naming the variable to set, before any request is sent (config
+// load already warns loudly).
 func BuildRegistry(c *Config) *provider.MapRegistry {
 	reg := provider.NewRegistry()
 	for _, p := range c.Providers {
@@ -27,7 +29,7 @@ func BuildRegistry(c *Config) *provider.MapRegistry {
 		case "anthropic":
 			reg.Register(&provider.Anthropic{
 				ModelName: p.Name, ModelID: p.Model,
-				APIKey: apiKey, BaseURL: p.BaseURL,
+				APIKey: apiKey, KeyEnv: p.APIKeyEnv, BaseURL: p.BaseURL,
 				InUSD: p.Cost.Input, OutUSD: p.Cost.Output, MaxCtx: p.MaxContext,
 				DefMaxTok: p.MaxTokens, DefTemp: p.Temp,
 			})
@@ -38,7 +40,7 @@ func BuildRegistry(c *Config) *provider.MapRegistry {
 			}
 			reg.Register(&provider.OpenAICompat{
 				ModelName: p.Name, ModelID: p.Model, VendorTag: p.Vendor,
-				APIKey: apiKey, BaseURL: baseURL, Reasoning: p.Reasoning,
+				APIKey: apiKey, KeyEnv: p.APIKeyEnv, BaseURL: baseURL, Reasoning: p.Reasoning,
 				InUSD: p.Cost.Input, OutUSD: p.Cost.Output, MaxCtx: p.MaxContext,
 				DefMaxTok: p.MaxTokens, DefTemp: p.Temp,
 			})
@@ -64,6 +66,7 @@ func BuildEmbedder(c *Config) *provider.OpenAIEmbedder {
 	return &provider.OpenAIEmbedder{
 		ModelID: c.Embedder.Model,
 		APIKey:  apiKey,
+		KeyEnv:  c.Embedder.APIKeyEnv,
 		BaseURL: c.Embedder.BaseURL,
 	}
 }
@@ -71,12 +74,20 @@ func BuildEmbedder(c *Config) *provider.OpenAIEmbedder {
 // BuildTools instantiates the tool registry from the tools block.
 func BuildTools(c *Config) *thinking.ToolRegistry {
 	reg := thinking.NewToolRegistry()
+	reg.RepeatLimit = c.Defaults.ToolRepeatLimit
+	reg.Timeout = time.Duration(c.Defaults.ToolTimeoutSeconds) * time.Second
+	reg.Redact = c.Policies.RedactSecrets == nil || *c.Policies.RedactSecrets
+	reg.ForbidNetwork = c.Policies.ForbidNetwork
 	for _, t := range c.Tools {
 		switch t.Kind {
 		case "web_search":
 			reg.Register(&thinking.WebSearch{})
 		case "file_read":
 			reg.Register(thinking.NewFileRead(t.Name, t.Root))
+		case "file_edit":
+			reg.Register(thinking.NewFileEdit(t.Name, t.Root))
+		case "file_search":
+			reg.Register(thinking.NewFileSearch(t.Name, t.Root))
 		}
 	}
 	return reg
@@ -3,23 +3,22 @@ package council
 import (
 	"context"
 	"encoding/json"
-
 	"fmt"
-	"github.com/stukennedy/kyotee/internal/budget"
 	"math"
 	"sort"
 	"strings"
 
+	"github.com/stukennedy/kyotee/internal/budget"
 	"github.com/stukennedy/kyotee/internal/events"
-	"github.com/stukennedy/kyotee/internal/jsonx"
 	"github.com/stukennedy/kyotee/internal/pipeline"
 	"github.com/stukennedy/kyotee/internal/provider"
+	"github.com/stukennedy/kyotee/internal/thinking"
 )
 
 // checkConsensus runs the configured detection method and emits
 // council.vote (per member, vote method) and council.consensus.
 // The returned summary is the winning choice/summary when available.
-func (c *Stage) checkConsensus(ctx context.Context, st *pipeline.State, members []*member, method string, emit events.Emitter, roundsUsed int) (bool, string) {
+func (c *Stage) checkConsensus(ctx context.Context, st *pipeline.State, members []*member, method string, emit events.Emitter, roundsUsed, roundsMax int) (bool, string) {
 	var reached bool
 	var summary string
 
@@ -34,7 +33,7 @@ func (c *Stage) checkConsensus(ctx context.Context, st *pipeline.State, members
 
 	emit(events.Event{
 		Kind: events.KindCouncilConsensus, Stage: c.ID(),
-		Payload: map[string]any{"reached": reached, "method": method, "rounds_used": roundsUsed},
+		Payload: map[string]any{"reached": reached, "method": method, "rounds_used": roundsUsed, "rounds_max": roundsMax},
 	})
 	return reached, summary
 }
@@ -65,10 +64,16 @@ func (c *Stage) checkVote(members []*member, emit events.Emitter) (bool, string)
 	if voters == 0 {
 		return false, ""
 	}
+	// Walk members, not the map: on a tie the earliest-declared member's
+	// choice wins, so the same votes always name the same winner (and the
+	// synthesis prompt built from it is reproducible).
 	best, bestCount := "", 0
-	for choice, n := range counts {
-		if n > bestCount {
-			best, bestCount = choice, n
+	for _, m := range members {
+		if !m.hasVote {
+			continue
+		}
+		if key := normalizeChoice(m.vote.Choice); counts[key] > bestCount {
+			best, bestCount = key, counts[key]
 		}
 	}
 	// Fraction is over all members: silent members can't create consensus.
@@ -134,23 +139,18 @@ func (c *Stage) checkJudge(ctx context.Context, st *pipeline.State, members []*m
 	if c.Referee == nil {
 		return false, ""
 	}
-	resp, err := c.Referee.Generate(ctx, provider.Request{
+	var v judgeVerdict
+	err := thinking.Verdict(ctx, c.Referee, provider.Request{
 		System: `You are the referee of a council debate. Decide whether the members' positions have converged on materially the same answer.
 Respond with JSON ONLY, no prose, no fences:
 {"converged": bool, "summary": "one-line shared answer if converged", "dissent": ["unresolved point", ...]}`,
 		Messages:  []provider.Message{provider.UserText("Positions:\n\n" + c.positionDigest(members))},
 		MaxTokens: 500,
 		Metadata:  map[string]string{"task_id": st.TaskID, "stage": c.ID(), "role": "judge"},
-	})
+	}, &v, c.VerdictRetries, st, emit, c.ID(), "judge")
 	if err != nil {
 		return false, ""
 	}
-	st.AddTurn(c.ID(), "judge", resp.Text(), resp.Usage)
-	budget.CheckWarn(&st.Budget, emit)
-	var v judgeVerdict
-	if err := jsonx.Parse(resp.Text(), &v); err != nil {
-		return false, ""
-	}
 	// Surface judge-noted holdouts even when the debate later converges or
 	// deadlocks another way — genuine disagreement must not be papered over.
 	if len(v.Dissent) > 0 {
@@ -163,7 +163,7 @@ Respond with JSON ONLY, no prose, no fences:
 
 // resolveDeadlock applies the configured OnDeadlock path when rounds are
 // exhausted without consensus (spec 06 §4).
-func (c *Stage) resolveDeadlock(ctx context.Context, st *pipeline.State, members []*member, emit events.Emitter, roundsUsed int) {
+func (c *Stage) resolveDeadlock(ctx context.Context, st *pipeline.State, members []*member, emit events.Emitter, roundsUsed, roundsMax int) {
 	mode := c.OnDeadlock
 	if mode == "" {
 		mode = "synthesis_notes_dissent"
@@ -194,7 +194,7 @@ func (c *Stage) resolveDeadlock(ctx context.Context, st *pipeline.State, members
 	st.Meta[MetaOutcome] = mode
 	emit(events.Event{
 		Kind: events.KindCouncilConsensus, Stage: c.ID(),
-		Payload: map[string]any{"reached": false, "method": mode, "rounds_used": roundsUsed},
+		Payload: map[string]any{"reached": false, "method": mode, "rounds_used": roundsUsed, "rounds_max": roundsMax},
 	})
 }
 
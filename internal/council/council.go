@@ -51,6 +51,7 @@ type Stage struct {
 	// council.require_vendor_diversity).
 	RequireVendorDiversity bool
 	MaxToolCalls           int // per-member tool-loop cap (defaults.tool_call_cap)
+	VerdictRetries         int // judge re-prompts on bad JSON (defaults.verdict_retries)
 	MaxTokens              int
 }
 
@@ -143,7 +144,7 @@ func (c *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 	roundsUsed := 0
 	consensusReached := false
 	for r := 1; r <= rounds; r++ {
-		if reached, summary := c.checkConsensus(ctx, st, members, method, emit, roundsUsed); reached {
+		if reached, summary := c.checkConsensus(ctx, st, members, method, emit, roundsUsed, rounds); reached {
 			consensusReached = true
 			st.Meta[MetaOutcome] = "consensus"
 			if summary != "" {
@@ -156,7 +157,7 @@ func (c *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 			st.Meta[MetaDissent] = dissentJSON(members)
 			emit(events.Event{
 				Kind: events.KindCouncilConsensus, Stage: c.ID(),
-				Payload: map[string]any{"reached": false, "method": "budget_halt", "rounds_used": roundsUsed},
+				Payload: map[string]any{"reached": false, "method": "budget_halt", "rounds_used": roundsUsed, "rounds_max": rounds},
 			})
 			return st, nil // Synthesis runs on whatever positions exist.
 		}
@@ -184,7 +185,7 @@ func (c *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 				budget.CheckWarn(&st.Budget, emit)
 				emit(events.Event{
 					Kind: events.KindCouncilRebuttal, Stage: c.ID(), Actor: m.p.Name(),
-					Payload: map[string]any{"model": m.p.Name(), "round": r, "text": text},
+					Payload: map[string]any{"model": m.p.Name(), "round": r, "rounds_max": rounds, "text": text},
 				})
 			}(m)
 		}
@@ -192,7 +193,7 @@ func (c *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 	}
 
 	if !consensusReached {
-		if reached, summary := c.checkConsensus(ctx, st, members, method, emit, roundsUsed); reached {
+		if reached, summary := c.checkConsensus(ctx, st, members, method, emit, roundsUsed, rounds); reached {
 			consensusReached = true
 			st.Meta[MetaOutcome] = "consensus"
 			if summary != "" {
@@ -202,7 +203,7 @@ func (c *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 	}
 
 	if !consensusReached {
-		c.resolveDeadlock(ctx, st, members, emit, roundsUsed)
+		c.resolveDeadlock(ctx, st, members, emit, roundsUsed, rounds)
 	}
 	return st, nil
 }
@@ -215,7 +216,7 @@ func (c *Stage) memberCall(ctx context.Context, p provider.Provider, st *pipelin
 	flagged := thinking.FlaggedTools(st)
 	req := provider.Request{
 		System: "You are one member of a council of AI models from different vendors debating a hard problem. Be substantive, concise, and willing to change your mind when another member's argument is better." +
-			thinking.ToolInstruction(flagged),
+			thinking.ToolInstruction(flagged) + thinking.StyleInstruction(st),
 		Messages:        []provider.Message{provider.UserText(prompt)},
 		ReasoningEffort: thinking.SolverEffort(st),
 		MaxTokens:       c.MaxTokens,
@@ -250,3 +250,19 @@ func TestDeadlockMajorityVote(t *testing.T) {
 		t.Fatalf("plurality winner should be A: %q", st.Meta[MetaWinner])
 	}
 }
+
+// A tied vote that still clears the threshold must name the same winner on
+// every run; map iteration order used to pick one at random.
+func TestVoteTieBreakIsDeterministic(t *testing.T) {
+	voted := func(name, choice string) *member {
+		return &member{p: &provider.Fake{ModelName: name}, hasVote: true, vote: vote{Choice: choice, Confidence: 0.8}}
+	}
+	members := []*member{voted("m1", "Beta"), voted("m2", "alpha"), voted("m3", "beta"), voted("m4", "Alpha")}
+	stage := &Stage{Consensus: ConsensusConfig{Method: "vote", Threshold: 0.5}}
+	for i := 0; i < 50; i++ {
+		reached, winner := stage.checkVote(members, func(events.Event) {})
+		if !reached || winner != "beta" {
+			t.Fatalf("run %d: (%v, %q), want first-declared tied choice beta", i, reached, winner)
+		}
+	}
+}
@@ -2,6 +2,8 @@
 // Everything observable in the engine is an Event; the TUI is a pure consumer.
 package events
 
+import "fmt"
+
 // Event kinds (v1 catalog, spec 01 §3).
 const (
 	KindTaskReceived     = "task.received"
@@ -13,12 +15,14 @@ const (
 	KindThinkingToolChk  = "thinking.tool_check"
 	KindToolCall         = "tool.call"
 	KindToolResult       = "tool.result"
+	KindModelDelta       = "model.delta"
 	KindBrainTurn        = "brain.turn"
 	KindCouncilOpening   = "council.opening"
 	KindCouncilRebuttal  = "council.rebuttal"
 	KindCouncilVote      = "council.vote"
 	KindCouncilConsensus = "council.consensus"
 	KindBudgetWarn       = "budget.warn"
+	KindConfigChanged    = "config.changed"
 	KindTaskFinal        = "task.final"
 	KindError            = "error"
 )
@@ -47,3 +51,13 @@ type Bus interface {
 	// History returns a copy of all retained events for a task.
 	History(taskID string) []Event
 }
+
+// RoundProgress formats a debate round against its cap ("2/3"), or just the
+// round when the cap is unknown (events from older engines). The TUI and the
+// remote client both render council and two-brain payloads with it.
+func RoundProgress(round, max int) string {
+	if max <= 0 {
+		return fmt.Sprintf("%d", round)
+	}
+	return fmt.Sprintf("%d/%d", round, max)
+}
@@ -0,0 +1,19 @@
+package events
+
+import "testing"
+
+func TestRoundProgress(t *testing.T) {
+	cases := []struct {
+		round, max int
+		want       string
+	}{
+		{2, 3, "2/3"},
+		{0, 5, "0/5"},
+		{2, 0, "2"}, // cap unknown: older engine payloads
+	}
+	for _, c := range cases {
+		if got := RoundProgress(c.round, c.max); got != c.want {
+			t.Fatalf("RoundProgress(%d, %d) = %q, want %q", c.round, c.max, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package logging configures the engine's diagnostic log: structured slog
+// records for operators debugging a run. It is separate from the event bus
+// (what a task did, shown in the TUI) and from CLI output (what a command
+// reports to its user).
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LevelEnv selects the minimum level: debug | info | warn | error.
+const LevelEnv = "KYOTEE_LOG_LEVEL"
+
+// DefaultFile is where --log writes.
+func DefaultFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".kyotee", "kyotee.log")
+	}
+	return filepath.Join(home, ".kyotee", "kyotee.log")
+}
+
+// ParseLevel maps a KYOTEE_LOG_LEVEL value to a slog level; "" is info.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("%s: unknown level %q (want debug|info|warn|error)", LevelEnv, s)
+}
+
+// New returns a text logger writing records at or above level to w.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Setup installs the process-wide default logger. Records go to console
+// (nil discards them — the TUI owns the terminal) and, when logFile is set,
+// are appended to that file too. The returned close func releases the file.
+func Setup(console io.Writer, logFile string) (func() error, error) {
+	level, err := ParseLevel(os.Getenv(LevelEnv))
+	if err != nil {
+		return nil, err
+	}
+	closer := func() error { return nil }
+	var sinks []io.Writer
+	if console != nil {
+		sinks = append(sinks, console)
+	}
+	if logFile != "" {
+		if err := os.MkdirAll(filepath.Dir(logFile), 0o755); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, f)
+		closer = f.Close
+	}
+	w := io.Discard
+	if len(sinks) > 0 {
+		w = io.MultiWriter(sinks...)
+	}
+	slog.SetDefault(New(w, level))
+	return closer, nil
+}
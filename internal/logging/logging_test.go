@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelFilterSuppressesDebugAtInfo(t *testing.T) {
+	level, err := ParseLevel("info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	log := New(&buf, level)
+	log.Debug("gate verdict", "task_id", "t1")
+	log.Info("task started", "task_id", "t1", "stage", "thinking")
+
+	out := buf.String()
+	if strings.Contains(out, "gate verdict") {
+		t.Fatalf("debug line leaked at info level:\n%s", out)
+	}
+	if !strings.Contains(out, "task started") || !strings.Contains(out, "task_id=t1") || !strings.Contains(out, "stage=thinking") {
+		t.Fatalf("info line missing or lacks attributes:\n%s", out)
+	}
+
+	if level, _ := ParseLevel("DEBUG"); level != slog.LevelDebug {
+		t.Fatalf("DEBUG parsed as %v", level)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("unknown level should be rejected")
+	}
+}
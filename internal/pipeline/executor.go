@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/stukennedy/kyotee/internal/events"
@@ -12,6 +13,10 @@ import (
 // stages. The current Draft is promoted to Final as the best-effort answer.
 var ErrBudgetExhausted = errors.New("budget exhausted")
 
+// ErrTokenBudgetExhausted is ErrBudgetExhausted's counterpart for the
+// per-task token ceiling (defaults.max_task_tokens).
+var ErrTokenBudgetExhausted = errors.New("token budget exceeded")
+
 // Store is the persistence surface the Executor checkpoints through.
 // internal/state provides the JSON-file implementation.
 type Store interface {
@@ -40,6 +45,10 @@ func (e *Executor) Execute(ctx context.Context, stages []Stage, st *State) (*Sta
 		if st.Budget.Exhausted() {
 			st.Final = st.Draft
 			e.persist(st, emit)
+			if st.Budget.TokensExhausted() {
+				e.emitFinal(st, emit, "token_budget_exhausted")
+				return st, ErrTokenBudgetExhausted
+			}
 			e.emitFinal(st, emit, "budget_exhausted")
 			return st, ErrBudgetExhausted
 		}
@@ -52,6 +61,7 @@ func (e *Executor) Execute(ctx context.Context, stages []Stage, st *State) (*Sta
 		spentBefore := st.Budget.SpentUSD
 		turnsBefore := len(st.Transcript)
 		start := time.Now()
+		slog.Debug("stage start", "task_id", st.TaskID, "stage", stage.ID(), "spent_usd", st.Budget.SpentUSD)
 		emit(events.Event{
 			Kind:  events.KindStageStart,
 			Stage: stage.ID(),
@@ -73,13 +83,18 @@ func (e *Executor) Execute(ctx context.Context, stages []Stage, st *State) (*Sta
 			if len(st.Transcript) > turnsBefore {
 				st.Transcript = st.Transcript[:turnsBefore]
 			}
+			slog.Warn("stage failed", "task_id", st.TaskID, "stage", stage.ID(), "err", err)
+			// A stage that failed because ctx was cancelled (user abort,
+			// wall-clock limit) is not the end of the story: the caller
+			// knows the cause and emits the terminal event naming it.
+			// Marking this one terminal would close SSE streams first.
 			emit(events.Event{
 				Kind:  events.KindError,
 				Stage: stage.ID(),
 				Payload: map[string]any{
 					"message":  err.Error(),
 					"stage":    stage.ID(),
-					"terminal": true, // run stops here; resumable from checkpoint
+					"terminal": ctx.Err() == nil, // run stops here; resumable from checkpoint
 				},
 			})
 			e.persist(st, emit)
@@ -88,6 +103,8 @@ func (e *Executor) Execute(ctx context.Context, stages []Stage, st *State) (*Sta
 
 		st.Checkpoints = append(st.Checkpoints, stage.ID())
 		e.persist(st, emit)
+		slog.Debug("stage end", "task_id", st.TaskID, "stage", stage.ID(),
+			"cost_delta_usd", st.Budget.SpentUSD-spentBefore, "duration", time.Since(start))
 		emit(events.Event{
 			Kind:  events.KindStageEnd,
 			Stage: stage.ID(),
@@ -114,6 +131,7 @@ func (e *Executor) persist(st *State, emit events.Emitter) {
 		return
 	}
 	if err := e.Store.Save(st); err != nil {
+		slog.Error("checkpoint save failed", "task_id", st.TaskID, "err", err)
 		emit(events.Event{
 			Kind:    events.KindError,
 			Payload: map[string]any{"message": "checkpoint save failed: " + err.Error()},
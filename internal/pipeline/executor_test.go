@@ -9,6 +9,7 @@ import (
 	"github.com/stukennedy/kyotee/internal/pipeline"
 	"github.com/stukennedy/kyotee/internal/provider"
 	"github.com/stukennedy/kyotee/internal/state"
+	"github.com/stukennedy/kyotee/internal/thinking"
 )
 
 type stubStage struct {
@@ -106,6 +107,36 @@ func TestBudgetHaltPromotesDraftToFinal(t *testing.T) {
 	}
 }
 
+// The token ceiling halts like the USD one: the first stage crosses it, no
+// later stage runs, and the final reason says which budget ran out.
+func TestTokenBudgetHaltsAfterFirstStage(t *testing.T) {
+	ex, bus := newExecutor(t)
+	st := pipeline.NewState("t2tok", "chatty")
+	st.Budget.TokenLimit = 500
+
+	first := &stubStage{id: "first", fn: func(s *pipeline.State, _ events.Emitter) error {
+		s.Draft = "partial"
+		s.Budget.Account(provider.Usage{InputTokens: 400, OutputTokens: 200})
+		return nil
+	}}
+	second := &stubStage{id: "second"}
+
+	got, err := ex.Execute(context.Background(), []pipeline.Stage{first, second}, st)
+	if !errors.Is(err, pipeline.ErrTokenBudgetExhausted) {
+		t.Fatalf("want ErrTokenBudgetExhausted, got %v", err)
+	}
+	if first.ran != 1 || second.ran != 0 {
+		t.Fatalf("ran first=%d second=%d, want 1/0", first.ran, second.ran)
+	}
+	if got.Final != "partial" {
+		t.Fatalf("Draft not promoted to Final: %q", got.Final)
+	}
+	hist := bus.History("t2tok")
+	if last := hist[len(hist)-1]; last.Kind != events.KindTaskFinal || last.Payload["reason"] != "token_budget_exhausted" {
+		t.Fatalf("expected task.final with token_budget_exhausted, got %+v", last)
+	}
+}
+
 func TestResumeSkipsCheckpointedStages(t *testing.T) {
 	ex, _ := newExecutor(t)
 	st := pipeline.NewState("t3", "resume me")
@@ -162,3 +193,34 @@ func TestEventFanOutToTwoSubscribers(t *testing.T) {
 		}
 	}
 }
+
+// A solo stage streams its answer onto the bus as model.delta events, ahead
+// of the task.final that carries it whole.
+func TestSoloStreamsDeltasToBus(t *testing.T) {
+	ex, bus := newExecutor(t)
+	solo := &thinking.Solo{Model: provider.NewFake("m", "anthropic", provider.TextResponse("streamed answer", 10, 10))}
+
+	if _, err := ex.Execute(context.Background(), []pipeline.Stage{solo}, pipeline.NewState("t1", "hello")); err != nil {
+		t.Fatal(err)
+	}
+	var text string
+	sawFinal := false
+	for _, ev := range bus.History("t1") {
+		switch ev.Kind {
+		case events.KindModelDelta:
+			if sawFinal {
+				t.Fatal("delta published after task.final")
+			}
+			if ev.Stage != "solo" || ev.Actor != "m" {
+				t.Fatalf("delta stage/actor = %q/%q", ev.Stage, ev.Actor)
+			}
+			chunk, _ := ev.Payload["text"].(string)
+			text += chunk
+		case events.KindTaskFinal:
+			sawFinal = true
+		}
+	}
+	if text != "streamed answer" || !sawFinal {
+		t.Fatalf("streamed %q, final seen %v", text, sawFinal)
+	}
+}
@@ -35,6 +35,9 @@ type BudgetState struct {
 	LimitUSD float64 `json:"limit_usd"`
 	SpentUSD float64 `json:"spent_usd"`
 	Tokens   int     `json:"tokens"`
+	// TokenLimit caps total tokens (input + output) across every call in
+	// the task, alongside the USD ceiling; 0 = unlimited.
+	TokenLimit int `json:"token_limit,omitempty"`
 	// WarnAt are the configured warn thresholds (receptionist.warn_thresholds);
 	// empty means the built-in 50/80/95% defaults.
 	WarnAt []float64 `json:"warn_at,omitempty"`
@@ -49,9 +52,15 @@ func (b *BudgetState) Account(u provider.Usage) {
 	b.Tokens += u.InputTokens + u.OutputTokens
 }
 
-// Exhausted reports whether the ceiling has been reached (0 limit = unlimited).
+// Exhausted reports whether either ceiling — USD or tokens — has been
+// reached (0 limit = unlimited).
 func (b *BudgetState) Exhausted() bool {
-	return b.LimitUSD > 0 && b.SpentUSD >= b.LimitUSD
+	return (b.LimitUSD > 0 && b.SpentUSD >= b.LimitUSD) || b.TokensExhausted()
+}
+
+// TokensExhausted reports whether the token ceiling has been reached.
+func (b *BudgetState) TokensExhausted() bool {
+	return b.TokenLimit > 0 && b.Tokens >= b.TokenLimit
 }
 
 // RemainingUSD returns budget headroom; -1 means unlimited.
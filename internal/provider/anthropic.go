@@ -1,22 +1,26 @@
 package provider
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
 // Anthropic adapts the Anthropic Messages API to the Provider interface.
-// v1 uses non-streaming HTTP; when Request.Stream is set the final text is
-// delivered as a single synthetic delta.
+// When Request.Stream is set the request is made with "stream": true and
+// deltas are forwarded as the SSE events arrive; the returned Response is
+// assembled from the same events, so tool calls work identically either way.
 type Anthropic struct {
 	ModelName  string // registry name, e.g. "claude-sonnet"
 	ModelID    string // vendor model id, e.g. "claude-sonnet-4-5"
 	APIKey     string
+	KeyEnv     string // env var APIKey was read from, named in the missing-key error
 	BaseURL    string // default https://api.anthropic.com/v1
 	InUSD      float64
 	OutUSD     float64
@@ -53,7 +57,28 @@ type anthropicMsg struct {
 	Content []map[string]any `json:"content"`
 }
 
+// anthropicBlock is one response content block, as decoded from a full
+// response or accumulated from stream events.
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	Thinking  string          `json:"thinking"`
+	Signature string          `json:"signature"`
+	Data      string          `json:"data"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
 func (a *Anthropic) Generate(ctx context.Context, req Request) (Response, error) {
+	if a.APIKey == "" {
+		return Response{}, errMissingKey("anthropic", a.ModelName, a.KeyEnv)
+	}
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = a.DefMaxTok
@@ -108,34 +133,31 @@ func (a *Anthropic) Generate(ctx context.Context, req Request) (Response, error)
 		delete(body, "temperature")
 	}
 
+	if req.Stream != nil {
+		body["stream"] = true
+		return a.stream(ctx, body, req.Stream)
+	}
+
 	raw, err := a.post(ctx, body)
 	if err != nil {
 		return Response{}, err
 	}
 
 	var apiResp struct {
-		Content []struct {
-			Type      string          `json:"type"`
-			Text      string          `json:"text"`
-			ID        string          `json:"id"`
-			Name      string          `json:"name"`
-			Input     json.RawMessage `json:"input"`
-			Thinking  string          `json:"thinking"`
-			Signature string          `json:"signature"`
-			Data      string          `json:"data"`
-		} `json:"content"`
-		StopReason string `json:"stop_reason"`
-		Usage      struct {
-			InputTokens  int `json:"input_tokens"`
-			OutputTokens int `json:"output_tokens"`
-		} `json:"usage"`
+		Content    []anthropicBlock `json:"content"`
+		StopReason string           `json:"stop_reason"`
+		Usage      anthropicUsage   `json:"usage"`
 	}
 	if err := json.Unmarshal(raw, &apiResp); err != nil {
 		return Response{}, fmt.Errorf("anthropic: decode response: %w", err)
 	}
+	return a.response(apiResp.Content, apiResp.StopReason, apiResp.Usage), nil
+}
 
-	resp := Response{StopReason: apiResp.StopReason}
-	for _, c := range apiResp.Content {
+// response maps decoded content blocks to the provider-agnostic Response.
+func (a *Anthropic) response(blocks []anthropicBlock, stopReason string, usage anthropicUsage) Response {
+	resp := Response{StopReason: stopReason}
+	for _, c := range blocks {
 		switch c.Type {
 		case "text":
 			resp.Content = append(resp.Content, Block{Type: "text", Text: c.Text})
@@ -155,15 +177,112 @@ func (a *Anthropic) Generate(ctx context.Context, req Request) (Response, error)
 		}
 	}
 	resp.Usage = Usage{
-		InputTokens:  apiResp.Usage.InputTokens,
-		OutputTokens: apiResp.Usage.OutputTokens,
-		CostUSD:      CostFor(a, apiResp.Usage.InputTokens, apiResp.Usage.OutputTokens),
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CostUSD:      CostFor(a, usage.InputTokens, usage.OutputTokens),
 	}
-	if req.Stream != nil {
-		req.Stream(Delta{Type: "text", Text: resp.Text()})
-		req.Stream(Delta{Type: "done"})
+	return resp
+}
+
+// stream consumes the Messages API SSE stream: content_block_start opens a
+// block, content_block_delta appends text, thinking, signature, or partial
+// tool-input JSON to it, and message_stop ends the response. Text and
+// thinking deltas are forwarded to emit as they arrive.
+func (a *Anthropic) stream(ctx context.Context, body map[string]any, emit func(Delta)) (Response, error) {
+	httpResp, err := a.do(ctx, body)
+	if err != nil {
+		return Response{}, err
 	}
-	return resp, nil
+	defer httpResp.Body.Close()
+
+	var (
+		blocks     []anthropicBlock
+		inputs     []string // partial tool-input JSON, by block index
+		stopReason string
+		usage      anthropicUsage
+	)
+	sc := bufio.NewScanner(httpResp.Body)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue // event:, id:, comments, and blank separators
+		}
+		var ev struct {
+			Type    string `json:"type"`
+			Index   int    `json:"index"`
+			Message struct {
+				Usage anthropicUsage `json:"usage"`
+			} `json:"message"`
+			ContentBlock anthropicBlock `json:"content_block"`
+			Delta        struct {
+				Type        string `json:"type"`
+				Text        string `json:"text"`
+				Thinking    string `json:"thinking"`
+				Signature   string `json:"signature"`
+				PartialJSON string `json:"partial_json"`
+				StopReason  string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage anthropicUsage `json:"usage"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &ev); err != nil {
+			return Response{}, fmt.Errorf("anthropic: decode stream event: %w", err)
+		}
+		switch ev.Type {
+		case "message_start":
+			usage = ev.Message.Usage
+		case "content_block_start":
+			for len(blocks) <= ev.Index {
+				blocks = append(blocks, anthropicBlock{})
+				inputs = append(inputs, "")
+			}
+			blocks[ev.Index] = ev.ContentBlock
+			if ev.ContentBlock.Type == "tool_use" {
+				emit(Delta{Type: "tool_use_start", Text: ev.ContentBlock.Name})
+			}
+		case "content_block_delta":
+			if ev.Index >= len(blocks) {
+				return Response{}, fmt.Errorf("anthropic: stream delta for unopened block %d", ev.Index)
+			}
+			b := &blocks[ev.Index]
+			switch ev.Delta.Type {
+			case "text_delta":
+				b.Text += ev.Delta.Text
+				emit(Delta{Type: "text", Text: ev.Delta.Text})
+			case "thinking_delta":
+				b.Thinking += ev.Delta.Thinking
+				emit(Delta{Type: "reasoning", Text: ev.Delta.Thinking})
+			case "signature_delta":
+				b.Signature += ev.Delta.Signature
+			case "input_json_delta":
+				inputs[ev.Index] += ev.Delta.PartialJSON
+			}
+		case "content_block_stop":
+			if ev.Index < len(blocks) && inputs[ev.Index] != "" {
+				blocks[ev.Index].Input = json.RawMessage(inputs[ev.Index])
+			}
+		case "message_delta":
+			if ev.Delta.StopReason != "" {
+				stopReason = ev.Delta.StopReason
+			}
+			if ev.Usage.OutputTokens > 0 {
+				usage.OutputTokens = ev.Usage.OutputTokens
+			}
+		case "error":
+			return Response{}, fmt.Errorf("anthropic: stream %s: %s", ev.Error.Type, ev.Error.Message)
+		case "message_stop":
+			emit(Delta{Type: "done"})
+			return a.response(blocks, stopReason, usage), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Response{}, fmt.Errorf("anthropic: read stream: %w", err)
+	}
+	return Response{}, fmt.Errorf("anthropic: stream ended before message_stop")
 }
 
 // encodeMessages maps provider-agnostic messages to the Messages API shape.
@@ -214,6 +333,17 @@ func (a *Anthropic) encodeMessages(msgs []Message) []anthropicMsg {
 }
 
 func (a *Anthropic) post(ctx context.Context, body map[string]any) ([]byte, error) {
+	resp, err := a.do(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// do sends a Messages API request and returns the open response, or an
+// error carrying the body when the status isn't 200.
+func (a *Anthropic) do(ctx context.Context, body map[string]any) (*http.Response, error) {
 	baseURL := a.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.anthropic.com/v1"
@@ -238,15 +368,12 @@ func (a *Anthropic) post(ctx context.Context, body map[string]any) ([]byte, erro
 	if err != nil {
 		return nil, fmt.Errorf("anthropic: %w", err)
 	}
-	defer resp.Body.Close()
-	raw, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
 	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("anthropic: status %d: %s", resp.StatusCode, truncate(string(raw), 500))
 	}
-	return raw, nil
+	return resp, nil
 }
 
 func truncate(s string, n int) string {
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -128,3 +129,104 @@ func TestAnthropicToolChoiceNone(t *testing.T) {
 		t.Fatalf("tool_choice none not sent: %v", (*captured)["tool_choice"])
 	}
 }
+
+// With Request.Stream set the adapter must request SSE, forward text deltas
+// as they arrive, and still assemble tool calls from input_json_delta chunks.
+func TestAnthropicStreaming(t *testing.T) {
+	var captured map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &captured)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range []string{
+			`{"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"check."}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"t1","name":"web_search","input":{}}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"query\": "}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"uk pm\"}"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":30}}`,
+			`{"type":"message_stop"}`,
+		} {
+			var typ struct{ Type string }
+			json.Unmarshal([]byte(ev), &typ)
+			w.Write([]byte("event: " + typ.Type + "\ndata: " + ev + "\n\n"))
+		}
+	}))
+	defer srv.Close()
+	a := &Anthropic{ModelName: "claude", ModelID: "claude-test", APIKey: "k", BaseURL: srv.URL}
+
+	var deltas []Delta
+	resp, err := a.Generate(context.Background(), Request{
+		Messages: []Message{UserText("who is the pm?")},
+		Tools:    []ToolDef{{Name: "web_search"}},
+		Stream:   func(d Delta) { deltas = append(deltas, d) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if captured["stream"] != true {
+		t.Fatalf("stream not requested: %v", captured["stream"])
+	}
+	var text []string
+	for _, d := range deltas {
+		if d.Type == "text" {
+			text = append(text, d.Text)
+		}
+	}
+	if len(text) != 2 || text[0] != "Let me " || deltas[len(deltas)-1].Type != "done" {
+		t.Fatalf("deltas not forwarded incrementally: %+v", deltas)
+	}
+	if resp.Text() != "Let me check." || resp.StopReason != "tool_use" {
+		t.Fatalf("response not assembled: %q %q", resp.Text(), resp.StopReason)
+	}
+	calls := resp.ToolCalls()
+	if len(calls) != 1 || calls[0].ID != "t1" || calls[0].Input["query"] != "uk pm" {
+		t.Fatalf("tool call not assembled: %+v", calls)
+	}
+	if resp.Usage.InputTokens != 12 || resp.Usage.OutputTokens != 30 {
+		t.Fatalf("usage = %+v", resp.Usage)
+	}
+}
+
+// A stream that closes without message_stop is an error, not a silent
+// partial answer.
+func TestAnthropicStreamTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`data: {"type":"content_block_start","index":0,"content_block":{"type":"text"}}` + "\n\n"))
+	}))
+	defer srv.Close()
+	a := &Anthropic{ModelName: "claude", ModelID: "claude-test", APIKey: "k", BaseURL: srv.URL}
+	_, err := a.Generate(context.Background(), Request{
+		Messages: []Message{UserText("hi")},
+		Stream:   func(Delta) {},
+	})
+	if err == nil {
+		t.Fatal("truncated stream should fail")
+	}
+}
+
+// A missing key fails before any request with a message naming the variable
+// to set, not a vendor 401 mid-run. Keyless local endpoints are unaffected.
+func TestMissingAPIKeyIsFriendly(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+	defer srv.Close()
+
+	a := &Anthropic{ModelName: "claude", ModelID: "claude-test", KeyEnv: "ANTHROPIC_API_KEY", BaseURL: srv.URL}
+	_, err := a.Generate(context.Background(), Request{Messages: []Message{UserText("hi")}})
+	if err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY is not set") || !strings.Contains(err.Error(), "export ANTHROPIC_API_KEY=") {
+		t.Fatalf("want a friendly missing-key error, got %v", err)
+	}
+	o := &OpenAICompat{ModelName: "gpt", ModelID: "gpt-test", VendorTag: "openai", KeyEnv: "OPENAI_API_KEY", BaseURL: srv.URL}
+	if _, err := o.Generate(context.Background(), Request{Messages: []Message{UserText("hi")}}); err == nil || !strings.HasPrefix(err.Error(), "openai: no API key") {
+		t.Fatalf("openai: want a friendly missing-key error, got %v", err)
+	}
+	if hit {
+		t.Fatal("no request should be sent without a key")
+	}
+}
@@ -19,6 +19,7 @@ type OpenAICompat struct {
 	ModelID    string // vendor model id, e.g. "gpt-5"
 	VendorTag  string // "openai" | "google" | "local" | ...
 	APIKey     string
+	KeyEnv     string // env var APIKey was read from; empty = no key needed (local)
 	BaseURL    string // e.g. https://api.openai.com/v1
 	InUSD      float64
 	OutUSD     float64
@@ -49,6 +50,9 @@ func (o *OpenAICompat) Capabilities() Capabilities {
 func (o *OpenAICompat) CostPer1M() (float64, float64) { return o.InUSD, o.OutUSD }
 
 func (o *OpenAICompat) Generate(ctx context.Context, req Request) (Response, error) {
+	if o.APIKey == "" && o.KeyEnv != "" {
+		return Response{}, errMissingKey(o.VendorTag, o.ModelName, o.KeyEnv)
+	}
 	msgs := o.encodeMessages(req.System, req.Messages)
 	body := map[string]any{
 		"model":    o.ModelID,
@@ -249,11 +253,15 @@ func (o *OpenAICompat) post(ctx context.Context, body map[string]any) ([]byte, e
 type OpenAIEmbedder struct {
 	ModelID    string
 	APIKey     string
+	KeyEnv     string // env var APIKey was read from; empty = no key needed
 	BaseURL    string
 	HTTPClient *http.Client
 }
 
 func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.APIKey == "" && e.KeyEnv != "" {
+		return nil, errMissingKey("embedder", e.ModelID, e.KeyEnv)
+	}
 	baseURL := e.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.openai.com/v1"
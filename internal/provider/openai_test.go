@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// The chat-completions tool shape differs from ours: definitions are wrapped
+// in {"type":"function","function":{...}}, call arguments travel as a JSON
+// string, and each tool result is its own role:"tool" message. Both
+// directions must translate losslessly or tool loops break on OpenAI-style
+// endpoints (vLLM, Ollama, Gemini's compat layer).
+func TestOpenAIToolCallTranslation(t *testing.T) {
+	var captured map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		captured = map[string]any{}
+		json.Unmarshal(raw, &captured)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"choices": [{
+				"finish_reason": "tool_calls",
+				"message": {
+					"content": "",
+					"tool_calls": [{"id": "call_2", "type": "function",
+						"function": {"name": "read_file", "arguments": "{\"path\": \"go.mod\"}"}}]
+				}
+			}],
+			"usage": {"prompt_tokens": 20, "completion_tokens": 7}
+		}`))
+	}))
+	defer srv.Close()
+	o := &OpenAICompat{ModelName: "local", ModelID: "qwen", VendorTag: "local", BaseURL: srv.URL}
+
+	resp, err := o.Generate(context.Background(), Request{
+		System: "be terse",
+		Messages: []Message{
+			UserText("what module is this?"),
+			{Role: "assistant", Content: []Block{
+				{Type: "text", Text: "checking"},
+				{Type: "tool_use", ToolCall: &ToolCall{ID: "call_1", Name: "web_search", Input: map[string]any{"query": "kyotee"}}},
+			}},
+			{Role: "tool", Content: []Block{
+				{Type: "tool_result", ToolResult: &ToolResult{CallID: "call_1", Content: "no results"}},
+			}},
+		},
+		Tools: []ToolDef{{Name: "read_file", Description: "read a file"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Response: arguments string → Input map.
+	calls := resp.ToolCalls()
+	if len(calls) != 1 || calls[0].ID != "call_2" || calls[0].Name != "read_file" || calls[0].Input["path"] != "go.mod" {
+		t.Fatalf("tool call not decoded: %+v", calls)
+	}
+	if resp.Text() != "" || resp.StopReason != "tool_calls" {
+		t.Fatalf("empty content should yield no text block: %+v", resp.Content)
+	}
+	if resp.Usage.InputTokens != 20 || resp.Usage.OutputTokens != 7 {
+		t.Fatalf("usage = %+v", resp.Usage)
+	}
+
+	// Request: tool definitions wrapped as functions.
+	tools := captured["tools"].([]any)
+	fn := tools[0].(map[string]any)["function"].(map[string]any)
+	if tools[0].(map[string]any)["type"] != "function" || fn["name"] != "read_file" || fn["parameters"] == nil {
+		t.Fatalf("tool definition not wrapped: %+v", tools[0])
+	}
+
+	// Request: system, user, assistant with tool_calls, tool result.
+	msgs := captured["messages"].([]any)
+	if len(msgs) != 4 || msgs[0].(map[string]any)["role"] != "system" {
+		t.Fatalf("messages = %+v", msgs)
+	}
+	assistant := msgs[2].(map[string]any)
+	if assistant["content"] != "checking" {
+		t.Fatalf("assistant text lost: %+v", assistant)
+	}
+	tc := assistant["tool_calls"].([]any)[0].(map[string]any)
+	args, _ := tc["function"].(map[string]any)["arguments"].(string)
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(args), &decoded); err != nil || decoded["query"] != "kyotee" || tc["id"] != "call_1" {
+		t.Fatalf("tool_use not encoded as tool_calls with string arguments: %+v", tc)
+	}
+	result := msgs[3].(map[string]any)
+	if result["role"] != "tool" || result["tool_call_id"] != "call_1" || result["content"] != "no results" {
+		t.Fatalf("tool result not encoded as role:tool: %+v", result)
+	}
+}
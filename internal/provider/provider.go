@@ -137,6 +137,16 @@ type ToolResult struct {
 	IsError bool   `json:"is_error,omitempty"`
 }
 
+// errMissingKey is returned before any request is sent when a provider's API
+// key is empty, so the operator sees which variable to set rather than a
+// vendor 401 halfway through a council round.
+func errMissingKey(vendor, model, env string) error {
+	if env == "" {
+		return fmt.Errorf("%s: no API key for %q — set api_key_env in the config", vendor, model)
+	}
+	return fmt.Errorf("%s: no API key for %q — %s is not set; export it (export %s=...) or point api_key_env at the variable that holds your key", vendor, model, env, env)
+}
+
 // CostFor computes USD cost for a token count against a provider's rates.
 func CostFor(p Provider, inputTokens, outputTokens int) float64 {
 	in, out := p.CostPer1M()
@@ -2,13 +2,13 @@ package receptionist
 
 import (
 	"context"
-
-	"github.com/stukennedy/kyotee/internal/budget"
+	"errors"
+	"fmt"
 
 	"github.com/stukennedy/kyotee/internal/events"
-	"github.com/stukennedy/kyotee/internal/jsonx"
 	"github.com/stukennedy/kyotee/internal/pipeline"
 	"github.com/stukennedy/kyotee/internal/provider"
+	"github.com/stukennedy/kyotee/internal/thinking"
 )
 
 const classifierSystem = `You classify incoming tasks for an AI orchestration engine. You do NOT solve the task.
@@ -31,7 +31,9 @@ var fallbackClass = pipeline.Classification{
 }
 
 // Classify runs the cheap classifier model and parses its strict-JSON verdict
-// defensively, falling back to a safe default on any failure.
+// defensively. An unparseable verdict is re-prompted up to
+// defaults.verdict_retries times with the error fed back, while budget
+// remains; after that, or on any other failure, the safe default applies.
 func (r *Receptionist) Classify(ctx context.Context, st *pipeline.State, emit events.Emitter) pipeline.Classification {
 	cfg := r.Cfg.Get()
 	model, err := r.resolve(cfg.Receptionist.Model)
@@ -40,22 +42,18 @@ func (r *Receptionist) Classify(ctx context.Context, st *pipeline.State, emit ev
 		return fallbackClass
 	}
 
-	resp, err := model.Generate(ctx, provider.Request{
+	req := provider.Request{
 		System:    classifierSystem,
 		Messages:  []provider.Message{provider.UserText("Task: " + st.Original)},
 		MaxTokens: 300,
 		Metadata:  map[string]string{"task_id": st.TaskID, "stage": "classify"},
-	})
-	if err != nil {
-		r.classifyWarn(emit, "classifier error: "+err.Error())
-		return fallbackClass
 	}
-	st.AddTurn("receptionist", "classifier", resp.Text(), resp.Usage)
-	budget.CheckWarn(&st.Budget, emit)
-
 	var class pipeline.Classification
-	if err := jsonx.Parse(resp.Text(), &class); err != nil {
-		r.classifyWarn(emit, "classifier parse failure: "+err.Error())
+	if err := thinking.Verdict(ctx, model, req, &class, cfg.Defaults.VerdictRetries, st, emit, "receptionist", "classifier"); err != nil {
+		if !errors.Is(err, thinking.ErrUnparseableVerdict) {
+			err = fmt.Errorf("classifier error: %w", err)
+		}
+		r.classifyWarn(emit, err.Error())
 		return fallbackClass
 	}
 	if !valid(class.Complexity, "trivial", "standard", "hard") {
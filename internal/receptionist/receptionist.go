@@ -6,6 +6,7 @@ package receptionist
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/stukennedy/kyotee/internal/budget"
@@ -24,8 +25,13 @@ type Route struct {
 	ThinkingMode string // "fast" | "slow" | "auto"
 	Models       config.Models
 	BudgetUSD    float64 // per-task ceiling (0 = inherit global default)
+	Fallback     bool    // no rule matched; the built-in default applies
 }
 
+// ErrNoRoute fails intake when no routing rule matches and
+// receptionist.on_no_match is "reject".
+var ErrNoRoute = errors.New("no route matches")
+
 // Overrides shallow-merge onto the effective config for one task (spec 07
 // §4): the TUI's "escalate this one to council" affordance. Zero values mean
 // "no override".
@@ -90,6 +96,20 @@ func (r *Receptionist) Intake(ctx context.Context, st *pipeline.State, ov Overri
 		return nil, err
 	}
 
+	// Classification spends too, so a new task runs it under the global
+	// ceiling (or the override) until the matched route's ceiling is known.
+	// Never lower an already-set limit on resume.
+	newLimit := st.Budget.LimitUSD == 0
+	if newLimit {
+		st.Budget.LimitUSD = cfg.BudgetDefaultUSD()
+	}
+	if ov.BudgetUSD > 0 {
+		st.Budget.LimitUSD = ov.BudgetUSD
+	}
+	if st.Budget.TokenLimit == 0 {
+		st.Budget.TokenLimit = cfg.Defaults.MaxTaskTokens
+	}
+
 	if st.Class.Complexity == "" {
 		st.Class = r.Classify(ctx, st, emit)
 	}
@@ -103,19 +123,23 @@ func (r *Receptionist) Intake(ctx context.Context, st *pipeline.State, ov Overri
 	})
 
 	route := MatchRoute(cfg, st.Class)
-	applyOverrides(&route, ov)
-
-	// Budget ceiling: override > route > global default. Never lower an
-	// already-set limit on resume.
-	if st.Budget.LimitUSD == 0 {
-		limit := route.BudgetUSD
-		if limit == 0 {
-			limit = cfg.BudgetDefaultUSD()
+	if route.Fallback {
+		desc := fmt.Sprintf("complexity=%s domain=%s tool_need=%s", st.Class.Complexity, st.Class.Domain, st.Class.ToolNeed)
+		// A forced strategy is the user's explicit choice, so it stands in
+		// for the missing rule even under reject.
+		if cfg.Receptionist.OnNoMatch == "reject" && ov.Strategy == "" {
+			return nil, fmt.Errorf("%w %s (receptionist.on_no_match=reject; add a route or force a strategy)", ErrNoRoute, desc)
 		}
-		st.Budget.LimitUSD = limit
+		emit(events.Event{
+			Kind: events.KindError, Actor: "receptionist",
+			Payload: map[string]any{"level": "warn", "message": "no route matches " + desc + " — using solo on " + cfg.Receptionist.Model},
+		})
 	}
-	if ov.BudgetUSD > 0 {
-		st.Budget.LimitUSD = ov.BudgetUSD
+	applyOverrides(&route, ov)
+
+	// Budget ceiling: override > route > global default.
+	if newLimit && ov.BudgetUSD == 0 && route.BudgetUSD > 0 {
+		st.Budget.LimitUSD = route.BudgetUSD
 	}
 	st.Budget.WarnAt = cfg.Receptionist.WarnThresholds
 
@@ -162,6 +186,7 @@ func MatchRoute(cfg *config.Config, class pipeline.Classification) Route {
 		Strategy:     "solo",
 		ThinkingMode: "auto",
 		Models:       config.Models{Primary: cfg.Receptionist.Model},
+		Fallback:     true,
 	}
 }
 
@@ -260,7 +285,9 @@ func (r *Receptionist) assemble(route Route, cfg *config.Config, ov Overrides) (
 			LowConfidenceBelow: cfg.Thinking.LowConfidenceBelow,
 			SlowTriggers:       cfg.Thinking.SlowTriggers,
 			MaxToolCalls:       cfg.Defaults.ToolCallCap,
+			VerdictRetries:     cfg.Defaults.VerdictRetries,
 		},
+		StyleRoot: workspaceRoot(cfg),
 	}}
 	models := map[string]any{"primary": primary.Name()}
 
@@ -309,6 +336,7 @@ func (r *Receptionist) assemble(route Route, cfg *config.Config, ov Overrides) (
 				Referee:    primary, Embedder: r.Embedder, Tools: r.Tools,
 				RequireVendorDiversity: cfg.Council.RequireVendorDiversity,
 				MaxToolCalls:           cfg.Defaults.ToolCallCap,
+				VerdictRetries:         cfg.Defaults.VerdictRetries,
 			},
 			&council.Synthesis{Model: primary},
 		)
@@ -358,6 +386,22 @@ func (r *Receptionist) resolveAll(names []string) []provider.Provider {
 	return out
 }
 
+// workspaceRoot is the project the file tools operate on — the root of the
+// first file_edit tool, else any file tool — or "" when none is configured.
+func workspaceRoot(cfg *config.Config) string {
+	root := ""
+	for _, t := range cfg.Tools {
+		switch {
+		case t.Root == "":
+		case t.Kind == "file_edit":
+			return t.Root
+		case root == "" && (t.Kind == "file_read" || t.Kind == "file_search"):
+			root = t.Root
+		}
+	}
+	return root
+}
+
 func defaultStr(s, def string) string {
 	if s == "" {
 		return def
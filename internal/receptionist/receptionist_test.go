@@ -2,6 +2,8 @@ package receptionist
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -75,6 +77,44 @@ func TestRoutingRulesFirstMatchWins(t *testing.T) {
 	}
 }
 
+func TestNoMatchingRoutePolicy(t *testing.T) {
+	cfg := testConfig()
+	cfg.Receptionist.Routes = cfg.Receptionist.Routes[:len(cfg.Receptionist.Routes)-1] // drop the catch-all
+	class := pipeline.Classification{Complexity: "standard", Domain: "creative", ToolNeed: "none", Confidence: 0.9}
+
+	// warn (default): fall back to solo on the classifier model, visibly.
+	r := newReceptionist(cfg)
+	st := pipeline.NewState("t", "write a haiku")
+	st.Class = class
+	var warned bool
+	stages, err := r.Intake(context.Background(), st, Overrides{}, func(ev events.Event) {
+		if ev.Kind == events.KindError && strings.Contains(fmt.Sprint(ev.Payload["message"]), "no route matches") {
+			warned = true
+		}
+	})
+	if err != nil {
+		t.Fatalf("warn mode should continue: %v", err)
+	}
+	if ids := stageIDs(stages); len(ids) < 2 || ids[1] != "solo" || !warned {
+		t.Fatalf("warn mode: stages %v, warned %v", ids, warned)
+	}
+
+	// reject: intake halts before any stage is assembled.
+	cfg.Receptionist.OnNoMatch = "reject"
+	r = newReceptionist(cfg)
+	st = pipeline.NewState("t", "write a haiku")
+	st.Class = class
+	if _, err := r.Intake(context.Background(), st, Overrides{}, func(events.Event) {}); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("reject mode: err = %v, want ErrNoRoute", err)
+	}
+	// ...unless the user forced a strategy.
+	st = pipeline.NewState("t", "write a haiku")
+	st.Class = class
+	if _, err := r.Intake(context.Background(), st, Overrides{Strategy: "solo"}, func(events.Event) {}); err != nil {
+		t.Fatalf("forced strategy should bypass reject: %v", err)
+	}
+}
+
 func stageIDs(stages []pipeline.Stage) []string {
 	ids := make([]string, len(stages))
 	for i, s := range stages {
@@ -200,6 +240,71 @@ func TestClassifierParseFailureFallsBack(t *testing.T) {
 	}
 }
 
+func TestClassifierRetriesWithParseFeedback(t *testing.T) {
+	cfg := testConfig()
+	r := newReceptionist(cfg)
+	cheap, _ := r.Registry.Get("cheap")
+	fake := cheap.(*provider.Fake)
+	fake.Script = []provider.Response{
+		provider.TextResponse("Looks like code to me.", 10, 10),
+		provider.TextResponse(`{"complexity":"standard","domain":"code","tool_need":"none","confidence":0.9,"rationale":"code"}`, 10, 10),
+	}
+
+	st := pipeline.NewState("t", "write a parser")
+	class := r.Classify(context.Background(), st, func(events.Event) {})
+	if class.Domain != "code" || class.Rationale != "code" {
+		t.Fatalf("expected the corrected verdict, got %+v", class)
+	}
+	if len(fake.Requests) != 2 {
+		t.Fatalf("expected 1 retry, got %d calls", len(fake.Requests))
+	}
+	msgs := fake.Requests[1].Messages
+	if len(msgs) != 3 || msgs[1].Role != "assistant" || msgs[1].Content[0].Text != "Looks like code to me." {
+		t.Fatalf("retry should replay the bad output as the assistant turn: %+v", msgs)
+	}
+	feedback := msgs[2].Content[0].Text
+	if !strings.Contains(feedback, "no JSON object found") || strings.Contains(feedback, "Looks like code to me.") {
+		t.Fatalf("feedback should carry the error only, not repeat the output: %q", feedback)
+	}
+	if len(st.Transcript) != 2 {
+		t.Fatalf("both attempts should be accounted, got %d turns", len(st.Transcript))
+	}
+
+	// Retries are bounded by defaults.verdict_retries before falling back.
+	fake.Script = []provider.Response{provider.TextResponse("still prose", 10, 10)}
+	fake.Requests = nil
+	if class := r.Classify(context.Background(), pipeline.NewState("t", "x"), func(events.Event) {}); class != fallbackClass {
+		t.Fatalf("expected fallback, got %+v", class)
+	}
+	if got, want := len(fake.Requests), cfg.Defaults.VerdictRetries+1; got != want {
+		t.Fatalf("calls = %d, want %d", got, want)
+	}
+
+}
+
+// A new task's classifier calls run under the global ceiling: once the
+// first attempt spends it, Intake gets no retries.
+func TestIntakeClassifierRetriesStopAtBudget(t *testing.T) {
+	cfg := testConfig()
+	cfg.Receptionist.BudgetDefaultUSD = 0.01
+	r := newReceptionist(cfg)
+	cheap, _ := r.Registry.Get("cheap")
+	fake := cheap.(*provider.Fake)
+	fake.InUSD, fake.OutUSD = 1000, 1000 // 20 tokens = $0.02
+	fake.Script = []provider.Response{provider.TextResponse("still prose", 10, 10)}
+
+	st := pipeline.NewState("t", "x")
+	if _, err := r.Intake(context.Background(), st, Overrides{}, func(events.Event) {}); err != nil {
+		t.Fatal(err)
+	}
+	if st.Class != fallbackClass {
+		t.Fatalf("expected fallback, got %+v", st.Class)
+	}
+	if len(fake.Requests) != 1 {
+		t.Fatalf("retried past an exhausted budget: %d calls", len(fake.Requests))
+	}
+}
+
 func TestOverridesForceStrategyAndModels(t *testing.T) {
 	cfg := testConfig()
 	r := newReceptionist(cfg)
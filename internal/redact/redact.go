@@ -0,0 +1,38 @@
+// Package redact masks credentials in text before it reaches a prompt, an
+// event payload, or the task store. It is pattern-based and deliberately
+// conservative: it targets shapes that are almost never anything but a
+// secret, so ordinary code and prose pass through untouched.
+package redact
+
+import "regexp"
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+var patterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// PEM private key blocks, header to footer.
+	{regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`), Mask},
+	// AWS access key IDs.
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), Mask},
+	// OpenAI/Anthropic-style keys (sk-..., sk-ant-..., sk-proj-...).
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`), Mask},
+	// GitHub tokens.
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}`), Mask},
+	// Authorization: Bearer <token>.
+	{regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9._~+/=-]{16,}`), "$1 " + Mask},
+	// Env-style assignments whose name ends in a secret word:
+	// PASSWORD=..., DB_PASSWORD: "...", GITHUB_TOKEN=..., AWS_SECRET_ACCESS_KEY=...
+	// (MAX_TOKENS=4096 is left alone.)
+	{regexp.MustCompile(`\b([A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|SECRET_KEY|TOKEN|API_KEY|APIKEY|ACCESS_KEY|PRIVATE_KEY))(\s*[:=]\s*)(?:"[^"\n]*"|'[^'\n]*'|[^\s"']+)`), "$1$2" + Mask},
+}
+
+// Secrets returns text with recognised credentials replaced by Mask.
+func Secrets(text string) string {
+	for _, p := range patterns {
+		text = p.re.ReplaceAllString(text, p.repl)
+	}
+	return text
+}
@@ -9,7 +9,10 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,8 +41,30 @@ type Engine struct {
 	embedder council.Embedder
 	tools    *thinking.ToolRegistry
 	running  map[string]bool
+	cancels  map[string]context.CancelCauseFunc
 }
 
+// Cancellation causes, so a stopped run is never mistaken for a completed
+// or failed one: a user abort (Cancel) or the run wall-clock cap
+// (defaults.max_run_seconds).
+var (
+	ErrTaskAborted = errors.New("task aborted")
+	ErrRunTimedOut = errors.New("run timed out")
+)
+
+// ErrEmptyTask rejects a task with no text (or only whitespace) before any
+// model is called.
+var ErrEmptyTask = errors.New("empty task: give the text of the task to solve")
+
+// MetaStatus records how a task's last run ended when that isn't evident
+// from Final alone; Resume clears it.
+const (
+	MetaConfig     = "config_fingerprint"
+	MetaStatus     = "status"
+	StatusAborted  = "aborted"
+	StatusTimedOut = "timed_out"
+)
+
 func NewEngine(cfg *config.Config, store *state.FileStore) *Engine {
 	e := &Engine{
 		Holder:  config.NewHolder(cfg),
@@ -47,6 +72,7 @@ func NewEngine(cfg *config.Config, store *state.FileStore) *Engine {
 		Store:   store,
 		elog:    newEventLog(store.Dir),
 		running: map[string]bool{},
+		cancels: map[string]context.CancelCauseFunc{},
 	}
 	e.rebuild(cfg)
 	// Persist every event to the per-task ndjson log (spec 02 §3): replay
@@ -111,8 +137,8 @@ func (e *Engine) receptionist() *receptionist.Receptionist {
 // §4). A non-empty threadID continues an existing conversation: the prior
 // turns are carried forward as context (see State.PromptBody).
 func (e *Engine) Submit(text string, ov receptionist.Overrides, threadID string) (taskID, thread string, err error) {
-	if text == "" {
-		return "", "", fmt.Errorf("empty task text")
+	if strings.TrimSpace(text) == "" {
+		return "", "", ErrEmptyTask
 	}
 	if err := ov.Validate(e.Holder.Get()); err != nil {
 		return "", "", err
@@ -139,19 +165,58 @@ func (e *Engine) Submit(text string, ov receptionist.Overrides, threadID string)
 	if ovJSON, err := json.Marshal(ov); err == nil {
 		st.Meta["overrides"] = string(ovJSON)
 	}
+	st.Meta[MetaConfig] = e.Holder.Get().Fingerprint()
 	// Save immediately so the task (and its ThreadID) is discoverable as a
 	// thread tip before its first stage checkpoints — rapid follow-ups thread
 	// correctly, and the task list shows it right away.
-	_ = e.Store.Save(st)
-
-	e.mu.Lock()
-	e.running[taskID] = true
-	e.mu.Unlock()
+	e.save(st)
 
-	go e.run(st, ov)
+	e.start(st, ov)
 	return taskID, st.ThreadID, nil
 }
 
+// RoutePlan is the dry-run answer (POST /v1/route): how a task would be
+// classified and routed, without running it.
+type RoutePlan struct {
+	Class    pipeline.Classification `json:"class"`
+	Strategy string                  `json:"strategy"`
+	Thinking string                  `json:"thinking"`
+	Pipeline []string                `json:"pipeline"`
+	Models   map[string]any          `json:"models"`
+	LimitUSD float64                 `json:"limit_usd"`
+	Notes    []string                `json:"notes,omitempty"` // e.g. preflight downgrades
+}
+
+// Route runs intake only — classification (one receptionist-model call),
+// routing, overrides, and budget preflight — and reports the resulting
+// pipeline. Nothing is persisted, published, or solved.
+func (e *Engine) Route(ctx context.Context, text string, ov receptionist.Overrides) (*RoutePlan, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyTask
+	}
+	st := pipeline.NewState("dry-run", text)
+	plan := &RoutePlan{}
+	emit := func(ev events.Event) {
+		switch ev.Kind {
+		case events.KindTaskRouted:
+			plan.Strategy, _ = ev.Payload["strategy"].(string)
+			plan.Thinking, _ = ev.Payload["thinking"].(string)
+			plan.Pipeline, _ = ev.Payload["pipeline"].([]string)
+			plan.Models, _ = ev.Payload["models"].(map[string]any)
+		case events.KindBudgetWarn:
+			if reason, ok := ev.Payload["reason"].(string); ok {
+				plan.Notes = append(plan.Notes, reason)
+			}
+		}
+	}
+	if _, err := e.receptionist().Intake(ctx, st, ov, emit); err != nil {
+		return nil, err
+	}
+	plan.Class = st.Class
+	plan.LimitUSD = st.Budget.LimitUSD
+	return plan, nil
+}
+
 // threadTip returns the most recent task in a thread (latest wins by ID, whose
 // timestamp prefix sorts lexicographically), or nil if the thread is unknown.
 // O(number of persisted tasks); fine for a single-user local store.
@@ -200,7 +265,32 @@ func (e *Engine) Resume(taskID string) error {
 	if raw := st.Meta["overrides"]; raw != "" {
 		_ = json.Unmarshal([]byte(raw), &ov)
 	}
-	go e.run(st, ov)
+	delete(st.Meta, MetaStatus) // a fresh run; the old outcome no longer applies
+	// Resume always runs under the current config; if it was edited since
+	// the task started, say so rather than silently re-routing.
+	if cur := e.Holder.Get().Fingerprint(); st.Meta[MetaConfig] != cur {
+		if prev := st.Meta[MetaConfig]; prev != "" {
+			events.EmitterFor(e.Bus, taskID)(events.Event{Kind: events.KindConfigChanged, Actor: "engine",
+				Payload: map[string]any{"previous": prev, "current": cur,
+					"message": "config changed since this task started; resuming under the current config"}})
+		}
+		st.Meta[MetaConfig] = cur
+	}
+	e.start(st, ov)
+	return nil
+}
+
+// Cancel aborts a running task. The run stops at the next provider call or
+// stage boundary, persists its checkpoints with status "aborted", and stays
+// resumable.
+func (e *Engine) Cancel(taskID string) error {
+	e.mu.Lock()
+	cancel, ok := e.cancels[taskID]
+	e.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("task %s is not running", taskID)
+	}
+	cancel(ErrTaskAborted)
 	return nil
 }
 
@@ -210,10 +300,25 @@ func (e *Engine) Running(taskID string) bool {
 	return e.running[taskID]
 }
 
-func (e *Engine) run(st *pipeline.State, ov receptionist.Overrides) {
+// start registers the task as running and cancellable, then runs it in the
+// background.
+func (e *Engine) start(st *pipeline.State, ov receptionist.Overrides) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	e.mu.Lock()
+	e.running[st.TaskID] = true
+	e.cancels[st.TaskID] = cancel
+	e.mu.Unlock()
+	go e.run(ctx, st, ov)
+}
+
+func (e *Engine) run(ctx context.Context, st *pipeline.State, ov receptionist.Overrides) {
 	defer func() {
 		e.mu.Lock()
+		if cancel, ok := e.cancels[st.TaskID]; ok {
+			cancel(nil)
+		}
 		delete(e.running, st.TaskID)
+		delete(e.cancels, st.TaskID)
 		e.mu.Unlock()
 	}()
 
@@ -221,22 +326,72 @@ func (e *Engine) run(st *pipeline.State, ov receptionist.Overrides) {
 	emit(events.Event{Kind: events.KindTaskReceived, Actor: "receptionist",
 		Payload: map[string]any{"text": st.Original}})
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	defer cancel()
+	runCtx := ctx
+	if d := e.Holder.Get().MaxRunDuration(); d > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, d, ErrRunTimedOut)
+		defer cancel()
+	}
 
-	stages, err := e.receptionist().Intake(ctx, st, ov, emit)
+	stages, err := e.receptionist().Intake(runCtx, st, ov, emit)
 	if err != nil {
+		if e.stopped(runCtx, st, emit) {
+			return
+		}
 		emit(events.Event{Kind: events.KindError,
 			Payload: map[string]any{"message": "intake failed: " + err.Error(), "terminal": true}})
-		_ = e.Store.Save(st)
+		slog.Warn("intake failed", "task_id", st.TaskID, "err", err)
+		e.save(st)
 		return
 	}
 
+	slog.Info("task started", "task_id", st.TaskID, "strategy", st.Meta["strategy"], "stages", len(stages))
 	ex := &pipeline.Executor{Store: e.Store, Bus: e.Bus}
-	if _, err := ex.Execute(ctx, stages, st); err != nil {
-		// Executor already emitted error / budget events and persisted state.
+	if _, err := ex.Execute(runCtx, stages, st); err != nil {
+		// Executor already emitted error / budget events and persisted
+		// state; an abort or timeout additionally records its status.
+		if e.stopped(runCtx, st, emit) {
+			return
+		}
+		slog.Warn("task failed", "task_id", st.TaskID, "err", err)
+		if runCtx.Err() != nil {
+			// Cancelled for a reason stopped doesn't name: the executor
+			// left the terminal event to us, so close the stream here.
+			emit(events.Event{Kind: events.KindError,
+				Payload: map[string]any{"message": "run cancelled: " + err.Error(), "terminal": true}})
+		}
 		return
 	}
+	slog.Info("task finished", "task_id", st.TaskID, "spent_usd", st.Budget.SpentUSD)
+}
+
+// save persists st; a failure only costs resumability, so it is logged
+// rather than failing the run.
+func (e *Engine) save(st *pipeline.State) {
+	if err := e.Store.Save(st); err != nil {
+		slog.Error("state save failed", "task_id", st.TaskID, "err", err)
+	}
+}
+
+// stopped records why a run was cut short — user abort or wall-clock
+// timeout — by persisting the status and emitting a terminal error naming
+// the cause. Reports false when the run stopped for any other reason.
+func (e *Engine) stopped(ctx context.Context, st *pipeline.State, emit events.Emitter) bool {
+	var status, msg string
+	switch cause := context.Cause(ctx); {
+	case errors.Is(cause, ErrTaskAborted):
+		status, msg = StatusAborted, "task aborted by user"
+	case errors.Is(cause, ErrRunTimedOut):
+		status, msg = StatusTimedOut, "run exceeded defaults.max_run_seconds"
+	default:
+		return false
+	}
+	st.Meta[MetaStatus] = status
+	slog.Info("task stopped", "task_id", st.TaskID, "status", status)
+	e.save(st)
+	emit(events.Event{Kind: events.KindError,
+		Payload: map[string]any{"message": msg, "status": status, "terminal": true}})
+	return true
 }
 
 // TaskInfo is the list-endpoint summary row.
@@ -246,9 +401,24 @@ type TaskInfo struct {
 	Original string  `json:"original"`
 	Final    string  `json:"final"`
 	Running  bool    `json:"running"`
+	Status   string  `json:"status"` // running | completed | aborted | timed_out | incomplete
 	SpentUSD float64 `json:"spent_usd"`
 }
 
+// taskStatus derives a task's lifecycle status. "incomplete" covers runs
+// that stopped on an error or an engine restart; they are resumable.
+func taskStatus(st *pipeline.State, running bool) string {
+	switch {
+	case running:
+		return "running"
+	case st.Meta[MetaStatus] != "":
+		return st.Meta[MetaStatus]
+	case st.Final != "":
+		return "completed"
+	}
+	return "incomplete"
+}
+
 func (e *Engine) Tasks() ([]TaskInfo, error) {
 	ids, err := e.Store.List()
 	if err != nil {
@@ -269,7 +439,8 @@ func (e *Engine) Tasks() ([]TaskInfo, error) {
 		}
 		out = append(out, TaskInfo{
 			TaskID: id, ThreadID: st.ThreadID, Original: st.Original, Final: st.Final,
-			Running: runningSnapshot[id], SpentUSD: st.Budget.SpentUSD,
+			Running: runningSnapshot[id], Status: taskStatus(st, runningSnapshot[id]),
+			SpentUSD: st.Budget.SpentUSD,
 		})
 	}
 	return out, nil
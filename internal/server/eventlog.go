@@ -3,6 +3,7 @@ package server
 import (
 	"bufio"
 	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -57,11 +58,14 @@ func (l *eventLog) append(ev events.Event) {
 	if !ok {
 		f, err = os.OpenFile(l.path(ev.TaskID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
 		if err != nil {
+			slog.Error("event log open failed", "task_id", ev.TaskID, "err", err)
 			return
 		}
 		l.files[ev.TaskID] = f
 	}
-	f.Write(append(data, '\n'))
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		slog.Error("event log write failed", "task_id", ev.TaskID, "kind", ev.Kind, "err", err)
+	}
 	if terminalEvent(ev) {
 		f.Close()
 		delete(l.files, ev.TaskID)
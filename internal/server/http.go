@@ -10,16 +10,20 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/stukennedy/kyotee/internal/events"
+	"github.com/stukennedy/kyotee/internal/pipeline"
 	"github.com/stukennedy/kyotee/internal/receptionist"
 )
 
 // Handler builds the engine's HTTP mux (spec 02 §3):
 //
 //	POST /v1/tasks                {text, thread_id?, overrides?} → 201 {task_id, thread_id}; invalid override → 400
+//	POST /v1/route                {text, overrides?} → RoutePlan (dry run: classify + route, no solve)
 //	GET  /v1/tasks                → [TaskInfo]
-//	GET  /v1/tasks/{id}           → persisted State snapshot
+//	GET  /v1/tasks/{id}           → persisted State snapshot + "status"
 //	GET  /v1/tasks/{id}/events    → SSE: replay from Seq 0, live tail, ": ping", "event: done"
+//	                                ?follow=false: replay only, then "event: done"
 //	POST /v1/tasks/{id}/resume    → 202
+//	POST /v1/tasks/{id}/cancel    → 202; not running → 409
 //	GET  /v1/config               → effective config (YAML; secrets are env names only)
 //	PUT  /v1/config               → hot-reload; 400 keeps old config live
 //	POST /v1/config/reload        → re-read config file from disk
@@ -46,6 +50,23 @@ func (e *Engine) Handler() http.Handler {
 		writeJSON(w, http.StatusCreated, map[string]string{"task_id": taskID, "thread_id": threadID})
 	})
 
+	mux.HandleFunc("POST /v1/route", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text      string                 `json:"text"`
+			Overrides receptionist.Overrides `json:"overrides"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			httpErr(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		plan, err := e.Route(r.Context(), body.Text, body.Overrides)
+		if err != nil {
+			httpErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, plan)
+	})
+
 	mux.HandleFunc("GET /v1/tasks", func(w http.ResponseWriter, r *http.Request) {
 		tasks, err := e.Tasks()
 		if err != nil {
@@ -56,12 +77,18 @@ func (e *Engine) Handler() http.Handler {
 	})
 
 	mux.HandleFunc("GET /v1/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
-		st, err := e.Store.Load(r.PathValue("id"))
+		id := r.PathValue("id")
+		st, err := e.Store.Load(id)
 		if err != nil {
 			httpErr(w, http.StatusNotFound, "unknown task")
 			return
 		}
-		writeJSON(w, http.StatusOK, st)
+		// The derived lifecycle status rides along, so a client asking
+		// about one task needn't list them all.
+		writeJSON(w, http.StatusOK, struct {
+			*pipeline.State
+			Status string `json:"status"`
+		}{st, taskStatus(st, e.Running(id))})
 	})
 
 	mux.HandleFunc("POST /v1/tasks/{id}/resume", func(w http.ResponseWriter, r *http.Request) {
@@ -72,6 +99,14 @@ func (e *Engine) Handler() http.Handler {
 		writeJSON(w, http.StatusAccepted, map[string]string{"status": "resuming"})
 	})
 
+	mux.HandleFunc("POST /v1/tasks/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if err := e.Cancel(r.PathValue("id")); err != nil {
+			httpErr(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "cancelling"})
+	})
+
 	mux.HandleFunc("GET /v1/tasks/{id}/events", e.handleSSE)
 
 	mux.HandleFunc("GET /v1/config", func(w http.ResponseWriter, r *http.Request) {
@@ -133,7 +168,8 @@ func terminalEvent(ev events.Event) bool {
 // order: persisted-log replay (survives engine restarts) deduplicated
 // against the live bus subscription, then live tail. Sends "event: done"
 // when the task reaches task.final or a terminal error, and ": ping"
-// heartbeats every 15s. The id: field carries Seq for client de-dup.
+// heartbeats every 15s. The id: field carries Seq for client de-dup. With
+// ?follow=false it stops after the replay, even for an unfinished task.
 func (e *Engine) handleSSE(w http.ResponseWriter, r *http.Request) {
 	taskID := r.PathValue("id")
 	flusher, ok := w.(http.Flusher)
@@ -176,6 +212,24 @@ func (e *Engine) handleSSE(w http.ResponseWriter, r *http.Request) {
 			finished = true
 		}
 	}
+	if r.URL.Query().Get("follow") == "false" {
+		// Replay only: include whatever the bus holds that hasn't reached
+		// the persisted log yet, without waiting for more.
+		for drained := false; !drained; {
+			select {
+			case ev, open := <-ch:
+				if !open {
+					drained = true
+					break
+				}
+				writeEvent(ev)
+			default:
+				drained = true
+			}
+		}
+		done()
+		return
+	}
 	flusher.Flush()
 	// A finished task with no new run coming: replay is complete, close.
 	if finished && !e.Running(taskID) {
@@ -3,7 +3,9 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/stukennedy/kyotee/internal/config"
 	"github.com/stukennedy/kyotee/internal/events"
+	"github.com/stukennedy/kyotee/internal/provider"
 	"github.com/stukennedy/kyotee/internal/receptionist"
 	"github.com/stukennedy/kyotee/internal/state"
 )
@@ -61,8 +64,18 @@ func waitForFinal(t *testing.T, e *Engine, taskID string) {
 	}
 }
 
-// readSSE collects events until "event: done" or timeout.
+// readSSE collects event kinds until "event: done" or timeout.
 func readSSE(t *testing.T, url string) (kinds []string, sawDone bool) {
+	t.Helper()
+	evs, sawDone := readSSEEvents(t, url)
+	for _, ev := range evs {
+		kinds = append(kinds, ev.Kind)
+	}
+	return kinds, sawDone
+}
+
+// readSSEEvents collects events until "event: done" or timeout.
+func readSSEEvents(t *testing.T, url string) (evs []events.Event, sawDone bool) {
 	t.Helper()
 	resp, err := http.Get(url)
 	if err != nil {
@@ -82,7 +95,7 @@ func readSSE(t *testing.T, url string) (kinds []string, sawDone bool) {
 		if strings.HasPrefix(line, "event: ") {
 			current = strings.TrimPrefix(line, "event: ")
 			if current == "done" {
-				return kinds, true
+				return evs, true
 			}
 		}
 		if strings.HasPrefix(line, "data: ") && current != "done" {
@@ -90,10 +103,10 @@ func readSSE(t *testing.T, url string) (kinds []string, sawDone bool) {
 			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
 				t.Fatalf("bad event JSON: %v", err)
 			}
-			kinds = append(kinds, ev.Kind)
+			evs = append(evs, ev)
 		}
 	}
-	return kinds, false
+	return evs, false
 }
 
 func TestSubmitStreamAndDone(t *testing.T) {
@@ -311,3 +324,237 @@ func TestResumeEndpoint(t *testing.T) {
 		t.Fatalf("resume of unknown task: %d", resp.StatusCode)
 	}
 }
+
+// ctxBlocker is a model that never answers: Generate blocks until the
+// run's ctx is cancelled and then fails with its error, as real adapters do.
+type ctxBlocker struct {
+	*provider.Fake
+	started chan struct{}
+}
+
+func newCtxBlocker(name string) *ctxBlocker {
+	return &ctxBlocker{Fake: provider.NewFake(name, "mock"), started: make(chan struct{}, 1)}
+}
+
+func (b *ctxBlocker) Generate(ctx context.Context, _ provider.Request) (provider.Response, error) {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return provider.Response{}, ctx.Err()
+}
+
+// streamUntilDone attaches to a task's live SSE stream and returns what it
+// saw once "event: done" arrives.
+func streamUntilDone(t *testing.T, url string) <-chan []events.Event {
+	t.Helper()
+	out := make(chan []events.Event, 1)
+	go func() {
+		evs, _ := readSSEEvents(t, url)
+		out <- evs
+	}()
+	return out
+}
+
+// assertStoppedWith checks that the stream's only terminal event is the
+// engine's stop event carrying status, so clients report the real cause.
+func assertStoppedWith(t *testing.T, evs []events.Event, status string) {
+	t.Helper()
+	var terminal []events.Event
+	for _, ev := range evs {
+		if terminalEvent(ev) {
+			terminal = append(terminal, ev)
+		}
+	}
+	if len(terminal) != 1 || terminal[0].Payload["status"] != status {
+		t.Fatalf("want exactly one terminal event with status %q, got %+v", status, terminal)
+	}
+}
+
+// Cancelling a running task must end it as "aborted", never "completed",
+// and the HTTP surface must refuse to cancel a task that isn't running.
+func TestCancelMarksTaskAborted(t *testing.T) {
+	e := newTestEngine(t, t.TempDir())
+	blocking := newCtxBlocker("mid")
+	e.mu.Lock()
+	e.registry.(*provider.MapRegistry).Register(blocking)
+	e.mu.Unlock()
+
+	taskID, _, err := e.Submit("a long job", receptionist.Overrides{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(e.Handler())
+	defer srv.Close()
+
+	// Wait until the solver is in flight, then attach and abort.
+	select {
+	case <-blocking.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("solver never started")
+	}
+	stream := streamUntilDone(t, srv.URL+"/v1/tasks/"+taskID+"/events")
+	time.Sleep(50 * time.Millisecond) // let the subscription replay and go live
+	resp, err := http.Post(srv.URL+"/v1/tasks/"+taskID+"/cancel", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("cancel: %d", resp.StatusCode)
+	}
+
+	select {
+	case evs := <-stream:
+		assertStoppedWith(t, evs, StatusAborted)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream never closed after abort")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for e.Running(taskID) {
+		if time.Now().After(deadline) {
+			t.Fatal("aborted task never stopped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tasks, _ := e.Tasks()
+	if len(tasks) != 1 || tasks[0].Status != StatusAborted || tasks[0].Final != "" {
+		t.Fatalf("want one aborted task without a final answer, got %+v", tasks)
+	}
+
+	resp, err = http.Post(srv.URL+"/v1/tasks/"+taskID+"/cancel", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("cancel of a stopped task: got %d, want 409", resp.StatusCode)
+	}
+}
+
+// A run past defaults.max_run_seconds is cut off mid-stage and recorded as
+// timed out, not completed; the timeout is the stream's terminal event.
+func TestRunWallClockLimit(t *testing.T) {
+	e := newTestEngine(t, t.TempDir())
+	cfg := mockConfig()
+	cfg.Defaults.MaxRunSeconds = 1
+	e.Holder.Set(cfg)
+	slow := newCtxBlocker("mid")
+	e.mu.Lock()
+	e.registry.(*provider.MapRegistry).Register(slow)
+	e.mu.Unlock()
+	srv := httptest.NewServer(e.Handler())
+	defer srv.Close()
+
+	taskID, _, err := e.Submit("a long job", receptionist.Overrides{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := streamUntilDone(t, srv.URL+"/v1/tasks/"+taskID+"/events")
+	select {
+	case evs := <-stream:
+		assertStoppedWith(t, evs, StatusTimedOut)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream never closed after the wall-clock limit")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for e.Running(taskID) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed-out task never stopped")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	st, err := e.Store.Load(taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Meta[MetaStatus] != StatusTimedOut || st.Final != "" {
+		t.Fatalf("want timed_out without a final answer, got status=%q final=%q", st.Meta[MetaStatus], st.Final)
+	}
+}
+
+// Resuming after the config was edited must announce the change, and only
+// once: the task then carries the new fingerprint.
+func TestResumeReportsConfigChange(t *testing.T) {
+	e := newTestEngine(t, t.TempDir())
+	taskID, _, err := e.Submit("hello", receptionist.Overrides{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForFinal(t, e, taskID)
+
+	changed := mockConfig()
+	changed.Defaults.BudgetUSD = 2
+	e.Holder.Set(changed)
+
+	waitStopped := func() {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for e.Running(taskID) {
+			if time.Now().After(deadline) {
+				t.Fatal("task never stopped")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	resume := func() int {
+		t.Helper()
+		waitStopped()
+		if err := e.Resume(taskID); err != nil {
+			t.Fatal(err)
+		}
+		waitStopped()
+		n := 0
+		for _, ev := range e.Bus.History(taskID) {
+			if ev.Kind == events.KindConfigChanged {
+				n++
+			}
+		}
+		return n
+	}
+	if n := resume(); n != 1 {
+		t.Fatalf("first resume after a config edit: %d config.changed events, want 1", n)
+	}
+	if n := resume(); n != 1 {
+		t.Fatalf("second resume under the same config re-announced the change (%d events)", n)
+	}
+}
+
+func TestWhitespaceTaskRejected(t *testing.T) {
+	e := newTestEngine(t, t.TempDir())
+	if _, _, err := e.Submit("  \n\t ", receptionist.Overrides{}, ""); !errors.Is(err, ErrEmptyTask) {
+		t.Fatalf("whitespace task: err=%v, want ErrEmptyTask", err)
+	}
+	if _, err := e.Route(context.Background(), " ", receptionist.Overrides{}); !errors.Is(err, ErrEmptyTask) {
+		t.Fatalf("whitespace dry run: err=%v, want ErrEmptyTask", err)
+	}
+	if tasks, _ := e.Tasks(); len(tasks) != 0 {
+		t.Fatalf("empty task was persisted: %+v", tasks)
+	}
+}
+
+// Tasks submitted within the same second share the timestamp prefix; the
+// random suffix must still keep every ID, and so every state file, apart.
+func TestTaskIDsUniqueInTightLoop(t *testing.T) {
+	dir := t.TempDir()
+	e := newTestEngine(t, dir)
+	const n = 20
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		id, _, err := e.Submit("task", receptionist.Overrides{}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate task ID %s after %d submits", id, i)
+		}
+		seen[id] = true
+	}
+	for id := range seen {
+		waitForFinal(t, e, id)
+	}
+	if ids, err := e.Store.List(); err != nil || len(ids) != n {
+		t.Fatalf("state files = %d (%v), want %d", len(ids), err, n)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/stukennedy/kyotee/internal/pipeline"
 )
@@ -55,7 +56,7 @@ func (s *FileStore) Save(st *pipeline.State) error {
 	if err != nil {
 		return fmt.Errorf("marshal state: %w", err)
 	}
-	tmp, err := os.CreateTemp(s.Dir, ".tmp-*")
+	tmp, err := os.CreateTemp(s.Dir, tmpPattern)
 	if err != nil {
 		return err
 	}
@@ -100,3 +101,86 @@ func (s *FileStore) List() ([]string, error) {
 	sort.Strings(ids)
 	return ids, nil
 }
+
+// tmpPattern names Save's scratch files. A crash between create and rename
+// leaves one behind; CleanTemp sweeps them.
+const tmpPattern = ".tmp-*"
+
+// Removal is one file a sweep removed — or, in a dry run, would remove.
+type Removal struct {
+	Path string
+	Size int64
+}
+
+// CleanTemp removes Save scratch files last modified before cutoff. The
+// cutoff keeps a sweep from racing a live engine's in-flight write. Task
+// state and event logs are never touched. With dryRun nothing is deleted.
+func (s *FileStore) CleanTemp(cutoff time.Time, dryRun bool) ([]Removal, error) {
+	matches, err := filepath.Glob(filepath.Join(s.Dir, tmpPattern))
+	if err != nil {
+		return nil, err
+	}
+	return RemoveOlder(matches, cutoff, dryRun)
+}
+
+// PruneTasks removes the records of tasks last saved before cutoff: the
+// state file and its sidecars (the engine's <id>.events.ndjson). The most
+// recently saved completed task is always kept, so there is a finished run
+// left to inspect. With dryRun nothing is deleted.
+func (s *FileStore) PruneTasks(cutoff time.Time, dryRun bool) ([]Removal, error) {
+	ids, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var old []string
+	keep, newest := "", time.Time{}
+	for _, id := range ids {
+		info, err := os.Stat(s.path(id))
+		if err != nil {
+			continue
+		}
+		if st, err := s.Load(id); err == nil && st.Final != "" && info.ModTime().After(newest) {
+			keep, newest = id, info.ModTime()
+		}
+		if info.ModTime().Before(cutoff) {
+			old = append(old, id)
+		}
+	}
+	var removed []Removal
+	for _, id := range old {
+		if id == keep {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(s.Dir, id+".*"))
+		if err != nil {
+			return removed, err
+		}
+		// The record goes as a whole, whatever its sidecars' own mtimes.
+		rm, err := RemoveOlder(files, time.Time{}, dryRun)
+		removed = append(removed, rm...)
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// RemoveOlder deletes the regular files among paths last modified before
+// cutoff; a zero cutoff matches any age. With dryRun the matches are only
+// listed.
+func RemoveOlder(paths []string, cutoff time.Time, dryRun bool) ([]Removal, error) {
+	var removed []Removal
+	for _, p := range paths {
+		info, err := os.Lstat(p)
+		if err != nil || !info.Mode().IsRegular() || (!cutoff.IsZero() && !info.ModTime().Before(cutoff)) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(p); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, Removal{Path: p, Size: info.Size()})
+	}
+	return removed, nil
+}
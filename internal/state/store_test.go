@@ -0,0 +1,62 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stukennedy/kyotee/internal/pipeline"
+)
+
+// Checkpoints from the executor and status writes from a cancel can land
+// on the same task at once; every Load must still see a whole state.
+func TestConcurrentSavesStayValid(t *testing.T) {
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(pipeline.NewState("t1", "task")); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for w := 0; w < 8; w++ {
+		wg.Add(2)
+		go func() { // writer: each save is a distinct snapshot
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				st := pipeline.NewState("t1", "task")
+				st.Draft = fmt.Sprintf("writer %d save %d", w, i)
+				st.Meta["status"] = "running"
+				if err := s.Save(st); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+		go func() { // reader: never a partial or empty file
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if _, err := s.Load("t1"); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	st, err := s.Load("t1")
+	if err != nil || st.TaskID != "t1" || st.Draft == "" {
+		t.Fatalf("final state = %+v, %v", st, err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(s.Dir, tmpPattern)); len(left) != 0 {
+		t.Fatalf("scratch files left behind: %v", left)
+	}
+}
@@ -0,0 +1,107 @@
+package thinking
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stukennedy/kyotee/internal/provider"
+)
+
+// FileEdit is the find-and-replace counterpart to FileRead: it swaps one
+// exact, unique occurrence of old_string for new_string inside the sandbox
+// root, so a one-line change never means re-emitting the whole file.
+type FileEdit struct {
+	name string
+	root string
+}
+
+func NewFileEdit(name, root string) *FileEdit {
+	if name == "" {
+		name = "edit_file"
+	}
+	return &FileEdit{name: name, root: root}
+}
+
+func (f *FileEdit) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Name: f.name,
+		Description: fmt.Sprintf("Edit a file (path relative to %s) by replacing one exact snippet. "+
+			"old_string must appear exactly once; include enough surrounding context to make it unique.", f.root),
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{
+					"type":        "string",
+					"description": "File path relative to the sandbox root",
+				},
+				"old_string": map[string]any{
+					"type":        "string",
+					"description": "Exact text to replace; must match exactly once",
+				},
+				"new_string": map[string]any{
+					"type":        "string",
+					"description": "Replacement text",
+				},
+			},
+			"required": []any{"path", "old_string", "new_string"},
+		},
+	}
+}
+
+func (f *FileEdit) Exec(ctx context.Context, input map[string]any) (string, error) {
+	rel, _ := input["path"].(string)
+	oldStr, _ := input["old_string"].(string)
+	newStr, _ := input["new_string"].(string)
+	if oldStr == "" {
+		return "", fmt.Errorf("%s: empty old_string", f.name)
+	}
+	target, err := sandboxPath(f.name, f.root, rel)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return "", err
+	}
+	switch n := strings.Count(string(data), oldStr); n {
+	case 1:
+	case 0:
+		return "", fmt.Errorf("%s: old_string not found in %s", f.name, rel)
+	default:
+		return "", fmt.Errorf("%s: old_string matches %d times in %s; add context to make it unique", f.name, n, rel)
+	}
+	out := strings.Replace(string(data), oldStr, newStr, 1)
+
+	// Temp file + rename so a failed write never leaves a half-edited file.
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".edit-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(out); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return "", err
+	}
+	// Last chance to honour a timeout or cancel: past the rename the edit
+	// has happened and must be reported as such.
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("edited %s: replaced 1 occurrence", rel), nil
+}
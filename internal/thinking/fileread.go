@@ -41,37 +41,48 @@ func (f *FileRead) Def() provider.ToolDef {
 	}
 }
 
+func (f *FileRead) ReadOnly() bool { return true }
+
 const fileReadLimit = 64 * 1024
 
 func (f *FileRead) Exec(_ context.Context, input map[string]any) (string, error) {
 	rel, _ := input["path"].(string)
-	if strings.TrimSpace(rel) == "" {
-		return "", fmt.Errorf("%s: empty path", f.name)
-	}
-	rootAbs, err := filepath.Abs(f.root)
+	target, err := sandboxPath(f.name, f.root, rel)
 	if err != nil {
 		return "", err
 	}
-	// Resolve symlinks on BOTH sides before the containment check — a
-	// symlink inside the root must not smuggle out-of-sandbox files into
-	// model prompts.
-	root, err := filepath.EvalSymlinks(rootAbs)
+	data, err := os.ReadFile(target)
 	if err != nil {
-		return "", fmt.Errorf("%s: resolve root: %w", f.name, err)
+		return "", err
 	}
-	target, err := filepath.EvalSymlinks(filepath.Join(rootAbs, rel))
+	if len(data) > fileReadLimit {
+		data = data[:fileReadLimit]
+	}
+	return string(data), nil
+}
+
+// sandboxPath resolves rel against root and rejects anything that lands
+// outside it. Symlinks are resolved on BOTH sides before the containment
+// check — a symlink inside the root must not smuggle out-of-sandbox files
+// into model prompts (or let edits escape).
+func sandboxPath(tool, root, rel string) (string, error) {
+	if strings.TrimSpace(rel) == "" {
+		return "", fmt.Errorf("%s: empty path", tool)
+	}
+	rootAbs, err := filepath.Abs(root)
 	if err != nil {
 		return "", err
 	}
-	if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
-		return "", fmt.Errorf("%s: path escapes sandbox root", f.name)
+	resolved, err := filepath.EvalSymlinks(rootAbs)
+	if err != nil {
+		return "", fmt.Errorf("%s: resolve root: %w", tool, err)
 	}
-	data, err := os.ReadFile(target)
+	target, err := filepath.EvalSymlinks(filepath.Join(rootAbs, rel))
 	if err != nil {
 		return "", err
 	}
-	if len(data) > fileReadLimit {
-		data = data[:fileReadLimit]
+	if target != resolved && !strings.HasPrefix(target, resolved+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s: path escapes sandbox root", tool)
 	}
-	return string(data), nil
+	return target, nil
 }
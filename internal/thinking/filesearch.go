@@ -0,0 +1,156 @@
+package thinking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/stukennedy/kyotee/internal/provider"
+)
+
+// FileSearch lists files under the sandbox root whose relative path matches
+// a glob, so a model can find what to read instead of guessing paths.
+type FileSearch struct {
+	name string
+	root string
+}
+
+func NewFileSearch(name, root string) *FileSearch {
+	if name == "" {
+		name = "search_files"
+	}
+	return &FileSearch{name: name, root: root}
+}
+
+func (f *FileSearch) Def() provider.ToolDef {
+	return provider.ToolDef{
+		Name: f.name,
+		Description: fmt.Sprintf("Find files under %s by glob on the relative path: `**/*.go` (any depth), "+
+			"`src/*.ts` (one directory), `*.md` (that name anywhere). Returns matching paths, one per line.", f.root),
+		Schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"pattern": map[string]any{
+					"type":        "string",
+					"description": "Glob relative to the sandbox root; ** matches any number of directories",
+				},
+			},
+			"required": []any{"pattern"},
+		},
+	}
+}
+
+func (f *FileSearch) ReadOnly() bool { return true }
+
+// fileSearchLimit caps how many paths one search returns.
+const fileSearchLimit = 200
+
+// errSearchFull stops the walk once fileSearchLimit matches are found.
+var errSearchFull = errors.New("limit reached")
+
+func (f *FileSearch) Exec(ctx context.Context, input map[string]any) (string, error) {
+	pattern, _ := input["pattern"].(string)
+	pattern = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(pattern)), "./")
+	if pattern == "" {
+		return "", fmt.Errorf("%s: empty pattern", f.name)
+	}
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return "", fmt.Errorf("%s: bad pattern %q: %w", f.name, pattern, err)
+	}
+	root, err := sandboxPath(f.name, f.root, ".")
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		// A timed-out or cancelled call stops walking rather than
+		// scanning on after its result has been reported.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return nil // unreadable entries are skipped, not fatal
+		}
+		if p == root {
+			return nil
+		}
+		if d.IsDir() {
+			if skipSearchDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if MatchGlob(pattern, rel) {
+			matches = append(matches, rel)
+			if len(matches) >= fileSearchLimit {
+				return errSearchFull
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSearchFull) {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "no files match " + pattern, nil
+	}
+	out := strings.Join(matches, "\n")
+	if len(matches) >= fileSearchLimit {
+		out += fmt.Sprintf("\n… stopped at %d matches; narrow the pattern", fileSearchLimit)
+	}
+	return out, nil
+}
+
+// skipSearchDir reports directories never worth searching: hidden ones
+// (.git, .kyotee) and dependency trees.
+func skipSearchDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor"
+}
+
+// MatchGlob reports whether a slash-separated relative path matches pattern.
+// Segments match with path.Match; a "**" segment matches zero or more whole
+// directories. A pattern with no slash matches the file name at any depth,
+// so `*.go` behaves like `**/*.go`.
+func MatchGlob(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for len(pat) > 1 && pat[1] == "**" {
+				pat = pat[1:]
+			}
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], segs[0]); !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
@@ -31,7 +31,7 @@ func (s *Solo) Run(ctx context.Context, st *pipeline.State, emit events.Emitter)
 
 	flagged := FlaggedTools(st)
 	req := provider.Request{
-		System:          soloSystem + ToolInstruction(flagged),
+		System:          soloSystem + ToolInstruction(flagged) + StyleInstruction(st),
 		Messages:        []provider.Message{provider.UserText(st.PromptBody())},
 		ReasoningEffort: SolverEffort(st),
 		MaxTokens:       s.MaxTokens,
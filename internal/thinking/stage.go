@@ -5,13 +5,11 @@ package thinking
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/stukennedy/kyotee/internal/budget"
-
 	"github.com/stukennedy/kyotee/internal/events"
-	"github.com/stukennedy/kyotee/internal/jsonx"
 	"github.com/stukennedy/kyotee/internal/pipeline"
 	"github.com/stukennedy/kyotee/internal/provider"
 )
@@ -21,6 +19,7 @@ const (
 	MetaMode   = "thinking.mode"   // "fast" | "slow"
 	MetaEffort = "thinking.effort" // "minimal" | "low" | "medium" | "high"
 	MetaTools  = "thinking.tools"  // comma-separated tool names, or ""
+	MetaStyle  = "thinking.style"  // detected code style lines, or ""
 )
 
 // Options tunes the stage (from config defaults + thinking block, spec 07).
@@ -30,6 +29,7 @@ type Options struct {
 	LowConfidenceBelow float64  // classifier confidence below this triggers slow
 	SlowTriggers       []string // enabled trigger names for the auto gate
 	MaxToolCalls       int      // defaults.tool_call_cap
+	VerdictRetries     int      // gate/pre-pass re-prompts on bad JSON (defaults.verdict_retries)
 }
 
 func (o Options) withDefaults() Options {
@@ -71,6 +71,9 @@ type Stage struct {
 	Prepass provider.Provider // tool-need pre-pass model; defaults to Gate
 	Tools   *ToolRegistry
 	Opts    Options
+	// StyleRoot is the workspace sampled for code style on code tasks;
+	// empty skips detection.
+	StyleRoot string
 }
 
 func (s *Stage) ID() string { return "thinking" }
@@ -104,10 +107,22 @@ func (s *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 	st.Meta[MetaMode] = mode
 	st.Meta[MetaEffort] = effort
 	st.Meta[MetaTools] = ""
+	st.Meta[MetaStyle] = ""
+	payload := map[string]any{"mode": mode, "effort": effort, "reason": reason}
+	if st.Class.Domain == "code" && s.StyleRoot != "" {
+		style, err := DetectStyle(ctx, s.StyleRoot)
+		if err != nil {
+			return st, err
+		}
+		if desc := style.String(); desc != "" {
+			st.Meta[MetaStyle] = desc
+			payload["style"] = desc
+		}
+	}
 
 	emit(events.Event{
 		Kind: events.KindThinkingMode, Stage: s.ID(), Actor: s.gateName(),
-		Payload: map[string]any{"mode": mode, "effort": effort, "reason": reason},
+		Payload: payload,
 	})
 
 	if mode == "slow" {
@@ -177,23 +192,20 @@ Slow triggers:
 Respond with JSON ONLY, no prose, no fences:
 {"needs_slow": bool, "reasons": ["trigger", ...], "suggested_tools": ["tool", ...]}`, triggerList.String())
 
-	resp, err := s.Gate.Generate(ctx, provider.Request{
+	var v gateVerdict
+	err := Verdict(ctx, s.Gate, provider.Request{
 		System:    system,
 		Messages:  []provider.Message{provider.UserText("Task: " + st.Original)},
 		MaxTokens: 300,
 		Metadata:  map[string]string{"task_id": st.TaskID, "stage": "thinking.gate"},
-	})
+	}, &v, opts.VerdictRetries, st, emit, s.ID(), "gate")
+	// Fail open to slow: a wasted slow pass is cheaper than a stale answer.
+	if errors.Is(err, ErrUnparseableVerdict) {
+		return "slow", "gate parse failure, defaulting slow", nil
+	}
 	if err != nil {
-		// Fail open to slow: a wasted slow pass is cheaper than a stale answer.
 		return "slow", "gate error, defaulting slow: " + err.Error(), nil
 	}
-	st.AddTurn(s.ID(), "gate", resp.Text(), resp.Usage)
-	budget.CheckWarn(&st.Budget, emit)
-
-	var v gateVerdict
-	if err := jsonx.Parse(resp.Text(), &v); err != nil {
-		return "slow", "gate parse failure, defaulting slow", nil
-	}
 	if v.NeedsSlow {
 		return "slow", "gate: " + strings.Join(v.Reasons, ","), v.SuggestedTools
 	}
@@ -227,23 +239,17 @@ Respond with JSON ONLY, no prose, no fences:
 {"must_look_up": ["fact", ...], "tools_to_use": ["tool", ...], "safe_from_memory": ["fact", ...], "verdict": "use_tools" | "answer_directly"}`,
 		strings.Join(s.Tools.Names(), ", "))
 
-	resp, err := prepass.Generate(ctx, provider.Request{
+	var v prePassVerdict
+	err := Verdict(ctx, prepass, provider.Request{
 		System:    system,
 		Messages:  []provider.Message{provider.UserText("Task: " + st.Original)},
 		MaxTokens: 400,
 		Metadata:  map[string]string{"task_id": st.TaskID, "stage": "thinking.prepass"},
-	})
+	}, &v, s.Opts.VerdictRetries, st, emit, s.ID(), "prepass")
 
 	// On any pre-pass failure (call error or unparseable output), fall back
 	// to the gate's suggestion.
-	var v prePassVerdict
-	parsed := false
-	if err == nil {
-		st.AddTurn(s.ID(), "prepass", resp.Text(), resp.Usage)
-		budget.CheckWarn(&st.Budget, emit)
-		parsed = jsonx.Parse(resp.Text(), &v) == nil
-	}
-	if !parsed {
+	if err != nil {
 		v = prePassVerdict{ToolsToUse: gateSuggested}
 		if len(gateSuggested) > 0 {
 			v.Verdict = "use_tools"
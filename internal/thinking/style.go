@@ -0,0 +1,212 @@
+package thinking
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stukennedy/kyotee/internal/pipeline"
+)
+
+// Style is the code style observed in a project's source files. Empty
+// fields mean there was not enough evidence to call it.
+type Style struct {
+	Indent     string // "tabs" | "spaces"
+	Width      int    // spaces per level when Indent == "spaces"
+	Quotes     string // "single" | "double" (languages where it is a choice)
+	Semicolons string // "always" | "never" (JS/TS)
+}
+
+// Sampling bounds keep detection cheap on large repositories: at most
+// styleMaxFiles files are read, and the walk gives up after visiting
+// styleMaxEntries entries even if few of them were source files.
+const (
+	styleMaxFiles   = 40
+	styleMaxLines   = 400
+	styleMaxEntries = 5000
+)
+
+// styleExts are the source files worth sampling; quoteExts/semiExts are the
+// subsets where quote style and semicolons are a matter of taste.
+var (
+	styleExts = map[string]bool{
+		".go": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true,
+		".py": true, ".rb": true, ".rs": true, ".java": true, ".c": true, ".h": true,
+		".cpp": true, ".cs": true, ".php": true, ".css": true, ".scss": true,
+	}
+	quoteExts = map[string]bool{".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true, ".py": true, ".rb": true, ".php": true}
+	semiExts  = map[string]bool{".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true}
+)
+
+var errStyleSampled = errors.New("sampled enough")
+
+// DetectStyle samples source files under root and reports the dominant
+// indentation, quote and semicolon conventions. Unreadable files are skipped
+// and an empty Style means nothing could be inferred; the only error is
+// ctx's, when the task is cancelled or times out mid-walk.
+func DetectStyle(ctx context.Context, root string) (Style, error) {
+	var c styleCounts
+	files, entries := 0, 0
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entries++; entries > styleMaxEntries {
+			return errStyleSampled
+		}
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != root && skipSearchDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if !styleExts[ext] || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		if c.scan(p, ext) {
+			files++
+		}
+		if files >= styleMaxFiles {
+			return errStyleSampled
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStyleSampled) {
+		return Style{}, err
+	}
+	return c.style(), nil
+}
+
+type styleCounts struct {
+	tabs, spaces   int
+	widths         map[int]int // indent step → occurrences
+	single, double int
+	semi, bare     int
+}
+
+func (c *styleCounts) scan(path, ext string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if c.widths == nil {
+		c.widths = map[int]int{}
+	}
+	sc := bufio.NewScanner(f)
+	prev := 0
+	for n := 0; n < styleMaxLines && sc.Scan(); n++ {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		switch line[0] {
+		case '\t':
+			c.tabs++
+		case ' ':
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+			// A leading " *" continues a block comment, not an indent level.
+			if !strings.HasPrefix(trimmed, "*") {
+				c.spaces++
+				if step := indent - prev; step > 0 && step <= 8 {
+					c.widths[step]++
+				}
+				prev = indent
+			}
+		default:
+			prev = 0
+		}
+		if isComment(trimmed) {
+			continue
+		}
+		if quoteExts[ext] {
+			c.single += strings.Count(trimmed, "'") / 2
+			c.double += strings.Count(trimmed, `"`) / 2
+		}
+		if semiExts[ext] {
+			switch last := trimmed[len(trimmed)-1]; {
+			case last == ';':
+				c.semi++
+			case strings.ContainsRune("{}[(,:=>+-*/&|?.", rune(last)):
+				// Openers, closers and continuations never take a semicolon.
+			default:
+				c.bare++
+			}
+		}
+	}
+	return true
+}
+
+func isComment(s string) bool {
+	return strings.HasPrefix(s, "//") || strings.HasPrefix(s, "#") ||
+		strings.HasPrefix(s, "/*") || strings.HasPrefix(s, "*")
+}
+
+// dominant picks a over b (or b over a) only on a clear majority.
+func dominant(a, b int, as, bs string) string {
+	switch {
+	case a+b < 4:
+		return ""
+	case a >= 2*b:
+		return as
+	case b >= 2*a:
+		return bs
+	}
+	return ""
+}
+
+func (c styleCounts) style() Style {
+	s := Style{
+		Indent:     dominant(c.tabs, c.spaces, "tabs", "spaces"),
+		Quotes:     dominant(c.single, c.double, "single", "double"),
+		Semicolons: dominant(c.semi, c.bare, "always", "never"),
+	}
+	if s.Indent == "spaces" {
+		best := 0
+		for w, n := range c.widths {
+			if n > c.widths[best] || (n == c.widths[best] && w < best) {
+				best = w
+			}
+		}
+		s.Width = best
+	}
+	return s
+}
+
+// String renders the detected conventions one per line, or "" if none.
+func (s Style) String() string {
+	var lines []string
+	switch {
+	case s.Indent == "spaces" && s.Width > 0:
+		lines = append(lines, fmt.Sprintf("- indentation: %d spaces", s.Width))
+	case s.Indent != "":
+		lines = append(lines, "- indentation: "+s.Indent)
+	}
+	if s.Quotes != "" {
+		lines = append(lines, "- string quotes: "+s.Quotes)
+	}
+	if s.Semicolons != "" {
+		lines = append(lines, "- semicolons: "+s.Semicolons)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// StyleInstruction is appended to a solver's system prompt when the Thinking
+// stage detected the project's code style, so generated code blends in.
+func StyleInstruction(st *pipeline.State) string {
+	style := st.Meta[MetaStyle]
+	if style == "" {
+		return ""
+	}
+	return "\n\nSTYLE (detected from the project's existing files — match it in any code you write):\n" + style
+}
@@ -2,8 +2,16 @@ package thinking
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stukennedy/kyotee/internal/events"
 	"github.com/stukennedy/kyotee/internal/pipeline"
@@ -123,6 +131,31 @@ func TestTimelessPromptStaysFast(t *testing.T) {
 	}
 }
 
+// A gate reply that isn't JSON is re-prompted with the parse error, not
+// treated as an immediate fail-open to slow.
+func TestGateRetriesUnparseableVerdict(t *testing.T) {
+	gate := provider.NewFake("gate", "anthropic",
+		provider.TextResponse("This looks like a simple question.", 20, 10),
+		provider.TextResponse(`{"needs_slow": false, "reasons": [], "suggested_tools": []}`, 20, 10))
+	st := pipeline.NewState("hm", "what is a hash map?")
+	st.Class = pipeline.Classification{Confidence: 0.95}
+	emit, _ := collect()
+
+	stage := &Stage{Mode: "auto", Gate: gate, Tools: fakeSearch("unused"), Opts: Options{VerdictRetries: 1}}
+	if _, err := stage.Run(context.Background(), st, emit); err != nil {
+		t.Fatal(err)
+	}
+	if st.Meta[MetaMode] != "fast" {
+		t.Fatalf("want the retried verdict (fast), got %q", st.Meta[MetaMode])
+	}
+	if len(gate.Requests) != 2 {
+		t.Fatalf("gate calls = %d, want 2", len(gate.Requests))
+	}
+	if fb := gate.Requests[1].Messages[2].Content[0].Text; !strings.Contains(fb, "could not be parsed") {
+		t.Fatalf("retry carries no parse feedback: %q", fb)
+	}
+}
+
 func TestExplicitUserFlagForcesSlow(t *testing.T) {
 	// Gate would say fast — the deterministic overlay must win without a call.
 	gate := provider.NewFake("gate", "anthropic",
@@ -198,3 +231,467 @@ func TestToolLoopTerminatesAtCap(t *testing.T) {
 		t.Fatalf("too many generate calls (%d) — loop not bounded", calls)
 	}
 }
+
+func TestToolLoopDetectsRepetition(t *testing.T) {
+	var sawNudge bool
+	stuck := &provider.Fake{ModelName: "stuck", VendorName: "anthropic",
+		ScriptFn: func(call int, req provider.Request) (provider.Response, error) {
+			for _, m := range req.Messages {
+				for _, b := range m.Content {
+					if b.ToolResult != nil && strings.Contains(b.ToolResult.Content, "repeating yourself") {
+						sawNudge = true
+					}
+				}
+			}
+			return provider.Response{
+				Content: []provider.Block{{Type: "tool_use", ToolCall: &provider.ToolCall{
+					ID: fmt.Sprintf("c%d", call), Name: "web_search", Input: map[string]any{"query": "same"},
+				}}},
+				StopReason: "tool_use",
+			}, nil
+		}}
+	tools := fakeSearch("result")
+	tools.RepeatLimit = 2
+	emit, _ := collect()
+
+	req := provider.Request{
+		Messages: []provider.Message{provider.UserText("go")},
+		Tools:    tools.Defs(),
+	}
+	_, _, err := RunToolLoop(context.Background(), stuck, req, tools, 10, emit, "solo")
+	if !errors.Is(err, ErrToolLoopStuck) {
+		t.Fatalf("identical rounds not detected: err=%v", err)
+	}
+	if !sawNudge {
+		t.Fatal("model was never told to change strategy before the loop failed")
+	}
+}
+
+func TestFileEditReplacesUniqueMatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("a := 1\nb := 2\nc := 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edit := NewFileEdit("", dir)
+	ctx := context.Background()
+
+	if _, err := edit.Exec(ctx, map[string]any{"path": "main.go", "old_string": "b := 2", "new_string": "b := 3"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "a := 1\nb := 3\nc := 1\n" {
+		t.Fatalf("unique match not replaced: %q", data)
+	}
+
+	_, err := edit.Exec(ctx, map[string]any{"path": "main.go", "old_string": "d := 4", "new_string": "x"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("no-match edit: err=%v", err)
+	}
+
+	_, err = edit.Exec(ctx, map[string]any{"path": "main.go", "old_string": ":= 1", "new_string": ":= 9"})
+	if err == nil || !strings.Contains(err.Error(), "matches 2 times") {
+		t.Fatalf("ambiguous edit: err=%v", err)
+	}
+	if data2, _ := os.ReadFile(path); string(data2) != string(data) {
+		t.Fatalf("failed edit modified the file: %q", data2)
+	}
+
+	_, err = edit.Exec(ctx, map[string]any{"path": "../outside", "old_string": "a", "new_string": "b"})
+	if err == nil {
+		t.Fatal("edit outside the sandbox root was allowed")
+	}
+}
+
+func TestFileSearchGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{
+		"main.go", "internal/a/a.go", "internal/a/b/deep.go", "internal/a/readme.md",
+		"src/app.ts", "src/lib/util.ts", "vendor/x/x.go", "node_modules/m/index.ts", ".git/hooks/h.go",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	search := NewFileSearch("", dir)
+	run := func(pattern string) string {
+		t.Helper()
+		out, err := search.Exec(context.Background(), map[string]any{"pattern": pattern})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if got := run("**/*.go"); got != "internal/a/a.go\ninternal/a/b/deep.go\nmain.go" {
+		t.Fatalf("**/*.go across nested dirs (vendor/hidden skipped) = %q", got)
+	}
+	if got := run("src/*.ts"); got != "src/app.ts" {
+		t.Fatalf("src/*.ts should match one directory only: %q", got)
+	}
+	if got := run("internal/**/*.md"); got != "internal/a/readme.md" {
+		t.Fatalf("internal/**/*.md = %q", got)
+	}
+	if got := run("*.ts"); got != "src/app.ts\nsrc/lib/util.ts" {
+		t.Fatalf("slashless pattern should match the name at any depth: %q", got)
+	}
+	if got := run("**/*.py"); !strings.HasPrefix(got, "no files match") {
+		t.Fatalf("no-match result = %q", got)
+	}
+	if _, err := search.Exec(context.Background(), map[string]any{"pattern": "[bad"}); err == nil {
+		t.Fatal("malformed pattern should fail")
+	}
+	// A cancelled (or timed-out) call stops walking.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := search.Exec(ctx, map[string]any{"pattern": "**/*.go"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled search err = %v", err)
+	}
+}
+
+func TestDetectStyle(t *testing.T) {
+	write := func(dir, name, body string) {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tabs := t.TempDir()
+	write(tabs, "app.js", "function greet(name) {\n\tconst msg = 'hi ' + name;\n\tif (name) {\n\t\tconsole.log('x', msg);\n\t\tconsole.log('y', 'z');\n\t}\n\treturn msg;\n}\n")
+	write(tabs, "node_modules/dep/index.js", "function f() {\n    return \"a\" + \"b\" + \"c\" + \"d\" + \"e\"\n}\n")
+	ctx := context.Background()
+	if got, err := DetectStyle(ctx, tabs); err != nil || got != (Style{Indent: "tabs", Quotes: "single", Semicolons: "always"}) {
+		t.Fatalf("tab-indented single-quoted JS = %#v, %v", got, err)
+	}
+
+	spaces := t.TempDir()
+	write(spaces, "src/app.ts", "export function greet(name: string) {\n  const msg = \"hi \" + name\n  if (name) {\n    console.log(\"x\", msg)\n    console.log(\"y\", \"z\")\n  }\n  return msg\n}\n")
+	got, _ := DetectStyle(ctx, spaces)
+	if got != (Style{Indent: "spaces", Width: 2, Quotes: "double", Semicolons: "never"}) {
+		t.Fatalf("2-space double-quoted TS = %#v", got)
+	}
+	if s := got.String(); s != "- indentation: 2 spaces\n- string quotes: double\n- semicolons: never" {
+		t.Fatalf("String() = %q", s)
+	}
+
+	if got, err := DetectStyle(ctx, t.TempDir()); err != nil || got != (Style{}) || got.String() != "" {
+		t.Fatalf("empty repo should detect nothing: %#v, %v", got, err)
+	}
+
+	// A cancelled task stops the walk instead of sampling on.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := DetectStyle(cancelled, tabs); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled walk err = %v", err)
+	}
+
+	// The walk is bounded by entries visited, not only by files sampled:
+	// past styleMaxEntries non-source files, the source file goes unseen.
+	crowded := t.TempDir()
+	for i := 0; i < styleMaxEntries; i++ {
+		write(crowded, fmt.Sprintf("a/%05d.txt", i), "")
+	}
+	write(crowded, "z/app.js", "function f() {\n\treturn 'x';\n}\n")
+	if got, err := DetectStyle(ctx, crowded); err != nil || got != (Style{}) {
+		t.Fatalf("walk not bounded by entries: %#v, %v", got, err)
+	}
+}
+
+func TestToolLoopTimesOutHungTool(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	tools := NewToolRegistry(&FuncTool{
+		Definition: provider.ToolDef{Name: "web_search"},
+		// Ignores ctx entirely: the loop must not depend on a read-only
+		// tool's cooperation.
+		Fn:        func(context.Context, map[string]any) (string, error) { <-block; return "late", nil },
+		NoEffects: true,
+	})
+	tools.Timeout = 20 * time.Millisecond
+
+	solver := provider.NewFake("solver", "anthropic",
+		provider.Response{
+			Content:    []provider.Block{{Type: "tool_use", ToolCall: &provider.ToolCall{ID: "c1", Name: "web_search", Input: map[string]any{"query": "q"}}}},
+			StopReason: "tool_use",
+		},
+		provider.TextResponse("answered without it", 10, 10),
+	)
+	emit, evs := collect()
+	req := provider.Request{Messages: []provider.Message{provider.UserText("go")}, Tools: tools.Defs()}
+	resp, _, err := RunToolLoop(context.Background(), solver, req, tools, 3, emit, "solo")
+	if err != nil {
+		t.Fatalf("timeout surfaced as a Go error: %v", err)
+	}
+	if resp.Text() != "answered without it" {
+		t.Fatalf("loop did not continue after the timeout: %q", resp.Text())
+	}
+	results := kinds(*evs, events.KindToolResult)
+	if len(results) != 1 {
+		t.Fatalf("want 1 tool.result, got %d", len(results))
+	}
+	out, _ := results[0].Payload["output"].(string)
+	if !strings.Contains(out, "timed out after") || results[0].Payload["is_error"] != true {
+		t.Fatalf("timeout not reported as a tool result: %+v", results[0].Payload)
+	}
+}
+
+// A mutating tool is never abandoned on timeout: whatever it does lands
+// before its result is reported, so the model is never told an edit failed
+// that then goes through.
+func TestToolTimeoutLeavesNoLateWrite(t *testing.T) {
+	var wrote atomic.Bool
+	tools := NewToolRegistry(&FuncTool{
+		Definition: provider.ToolDef{Name: "edit_file"},
+		// Overruns its timeout without checking ctx, then writes.
+		Fn: func(context.Context, map[string]any) (string, error) {
+			time.Sleep(60 * time.Millisecond)
+			wrote.Store(true)
+			return "edited", nil
+		},
+	})
+	tools.Timeout = 20 * time.Millisecond
+
+	solver := provider.NewFake("solver", "anthropic",
+		provider.Response{
+			Content:    []provider.Block{{Type: "tool_use", ToolCall: &provider.ToolCall{ID: "c1", Name: "edit_file", Input: map[string]any{"path": "a"}}}},
+			StopReason: "tool_use",
+		},
+		provider.TextResponse("done", 10, 10),
+	)
+	var wroteAtResult bool
+	emit := func(ev events.Event) {
+		if ev.Kind == events.KindToolResult {
+			wroteAtResult = wrote.Load()
+		}
+	}
+	req := provider.Request{Messages: []provider.Message{provider.UserText("go")}, Tools: tools.Defs()}
+	if _, _, err := RunToolLoop(context.Background(), solver, req, tools, 3, emit, "solo"); err != nil {
+		t.Fatal(err)
+	}
+	if !wroteAtResult {
+		t.Fatal("the write landed after its tool.result was reported")
+	}
+
+	// The built-in editor honours ctx: a call that has timed out does not
+	// touch the file.
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewFileEdit("", dir).Exec(ctx, map[string]any{"path": "a.txt", "old_string": "old", "new_string": "new"}); err == nil {
+		t.Fatal("edit ran on a cancelled ctx")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Fatalf("file changed to %q", data)
+	}
+}
+
+func TestToolLoopCachesReadsUntilWrite(t *testing.T) {
+	reads := 0
+	tools := NewToolRegistry(
+		&FuncTool{
+			Definition: provider.ToolDef{Name: "read_file"},
+			Fn:         func(context.Context, map[string]any) (string, error) { reads++; return "contents", nil },
+			NoEffects:  true,
+		},
+		&FuncTool{
+			Definition: provider.ToolDef{Name: "edit_file"},
+			Fn:         func(context.Context, map[string]any) (string, error) { return "edited", nil },
+		},
+	)
+	tools.RepeatLimit = 10 // the third identical read round is not what's under test
+	call := func(id, name string) provider.Response {
+		return provider.Response{
+			Content:    []provider.Block{{Type: "tool_use", ToolCall: &provider.ToolCall{ID: id, Name: name, Input: map[string]any{"path": "main.go"}}}},
+			StopReason: "tool_use",
+		}
+	}
+	solver := provider.NewFake("solver", "anthropic",
+		call("c1", "read_file"),
+		call("c2", "read_file"), // cache hit
+		call("c3", "edit_file"), // invalidates
+		call("c4", "read_file"), // re-executes
+		provider.TextResponse("done", 10, 10),
+	)
+	emit, evs := collect()
+	req := provider.Request{Messages: []provider.Message{provider.UserText("go")}, Tools: tools.Defs()}
+	if _, _, err := RunToolLoop(context.Background(), solver, req, tools, 10, emit, "solo"); err != nil {
+		t.Fatal(err)
+	}
+	if reads != 2 {
+		t.Fatalf("read_file executed %d times, want 2 (one cache hit, one after the edit)", reads)
+	}
+	var cached []bool
+	for _, ev := range kinds(*evs, events.KindToolResult) {
+		cached = append(cached, ev.Payload["cached"] == true)
+	}
+	if fmt.Sprint(cached) != "[false true false false]" {
+		t.Fatalf("cached flags per result = %v", cached)
+	}
+}
+
+// read→edit→read→edit→read of one file is progress, not a loop: each read
+// after an edit sees new content, so edits reset the repeat count.
+func TestToolLoopEditsResetRepeatCount(t *testing.T) {
+	version := 0
+	tools := NewToolRegistry(
+		&FuncTool{
+			Definition: provider.ToolDef{Name: "read_file"},
+			Fn:         func(context.Context, map[string]any) (string, error) { return fmt.Sprintf("v%d", version), nil },
+			NoEffects:  true,
+		},
+		&FuncTool{
+			Definition: provider.ToolDef{Name: "edit_file"},
+			Fn:         func(context.Context, map[string]any) (string, error) { version++; return "edited", nil },
+		},
+	)
+	tools.RepeatLimit = 2
+	call := func(id, name, edit string) provider.Response {
+		input := map[string]any{"path": "main.go"}
+		if edit != "" {
+			input["new"] = edit
+		}
+		return provider.Response{
+			Content:    []provider.Block{{Type: "tool_use", ToolCall: &provider.ToolCall{ID: id, Name: name, Input: input}}},
+			StopReason: "tool_use",
+		}
+	}
+	solver := provider.NewFake("solver", "anthropic",
+		call("c1", "read_file", ""), call("c2", "edit_file", "fix 1"),
+		call("c3", "read_file", ""), call("c4", "edit_file", "fix 2"),
+		call("c5", "read_file", ""), call("c6", "edit_file", "fix 3"),
+		call("c7", "read_file", ""),
+		provider.TextResponse("done", 10, 10),
+	)
+	emit, evs := collect()
+	req := provider.Request{Messages: []provider.Message{provider.UserText("go")}, Tools: tools.Defs()}
+	resp, _, err := RunToolLoop(context.Background(), solver, req, tools, 20, emit, "solo")
+	if err != nil {
+		t.Fatalf("interleaved edits treated as a loop: %v", err)
+	}
+	if resp.Text() != "done" {
+		t.Fatalf("final = %q", resp.Text())
+	}
+	for _, ev := range kinds(*evs, events.KindToolResult) {
+		if out, _ := ev.Payload["output"].(string); strings.Contains(out, "repeating yourself") {
+			t.Fatalf("nudged despite edits in between: %+v", ev.Payload)
+		}
+	}
+	if version != 3 {
+		t.Fatalf("edits executed = %d, want 3", version)
+	}
+}
+
+// With Redact on, a credential in tool output — or echoed by a tool error —
+// never reaches the model or the tool.result event.
+func TestToolLoopRedactsSecretsInToolOutput(t *testing.T) {
+	tools := NewToolRegistry(&FuncTool{
+		Definition: provider.ToolDef{Name: "read_file"},
+		Fn: func(_ context.Context, in map[string]any) (string, error) {
+			if in["path"] == "bad.env" {
+				return "", errors.New("bad.env line 2: unexpected token in GITHUB_TOKEN=ghp_abcdefghijklmnop1234")
+			}
+			return "DB_HOST=localhost\nOPENAI_API_KEY=sk-proj-abcdefghijklmnop1234\n", nil
+		},
+	})
+	tools.Redact = true
+	solver := provider.NewFake("solver", "anthropic",
+		provider.Response{
+			Content: []provider.Block{
+				{Type: "tool_use", ToolCall: &provider.ToolCall{ID: "c1", Name: "read_file", Input: map[string]any{"path": ".env"}}},
+				{Type: "tool_use", ToolCall: &provider.ToolCall{ID: "c2", Name: "read_file", Input: map[string]any{"path": "bad.env"}}},
+			},
+			StopReason: "tool_use",
+		},
+		provider.TextResponse("done", 10, 10),
+	)
+	emit, evs := collect()
+	req := provider.Request{Messages: []provider.Message{provider.UserText("go")}, Tools: tools.Defs()}
+	if _, _, err := RunToolLoop(context.Background(), solver, req, tools, 4, emit, "solo"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := solver.Requests[1].Messages
+	results := msgs[len(msgs)-1].Content
+	sent := results[0].ToolResult.Content
+	if strings.Contains(sent, "sk-proj") || !strings.Contains(sent, "OPENAI_API_KEY=[REDACTED]") || !strings.Contains(sent, "DB_HOST=localhost") {
+		t.Fatalf("tool result sent to model = %q", sent)
+	}
+	if failed := results[1].ToolResult.Content; strings.Contains(failed, "ghp_") || !strings.Contains(failed, "tool error: bad.env") {
+		t.Fatalf("tool error sent to model = %q", failed)
+	}
+	for _, ev := range kinds(*evs, events.KindToolResult) {
+		if out := ev.Payload["output"].(string); strings.Contains(out, "sk-proj") || strings.Contains(out, "ghp_") {
+			t.Fatalf("secret leaked into tool.result event: %v", out)
+		}
+	}
+}
+
+// With forbid_network set, web_search never reaches the network: the model
+// gets a policy-violation tool result instead, and the loop carries on.
+func TestToolLoopForbidNetworkBlocksWebSearch(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+	defer srv.Close()
+	tools := NewToolRegistry(&WebSearch{BaseURL: srv.URL})
+	tools.ForbidNetwork = true
+
+	solver := provider.NewFake("solver", "anthropic",
+		provider.Response{
+			Content:    []provider.Block{{Type: "tool_use", ToolCall: &provider.ToolCall{ID: "c1", Name: "web_search", Input: map[string]any{"query": "uk pm"}}}},
+			StopReason: "tool_use",
+		},
+		provider.TextResponse("could not verify", 10, 10),
+	)
+	emit, _ := collect()
+	req := provider.Request{Messages: []provider.Message{provider.UserText("who is the pm?")}, Tools: tools.Defs()}
+	if _, _, err := RunToolLoop(context.Background(), solver, req, tools, 4, emit, "solo"); err != nil {
+		t.Fatal(err)
+	}
+	if hit {
+		t.Fatal("web_search reached the network despite forbid_network")
+	}
+	msgs := solver.Requests[1].Messages
+	res := msgs[len(msgs)-1].Content[0].ToolResult
+	if !res.IsError || !strings.Contains(res.Content, "forbid_network") {
+		t.Fatalf("want a policy-violation tool result, got %+v", res)
+	}
+}
+
+// A blank answer is retried once with a nudge; a second blank one fails
+// clearly instead of producing an empty Final.
+func TestToolLoopRetriesEmptyOutput(t *testing.T) {
+	solver := provider.NewFake("solver", "anthropic",
+		provider.TextResponse("  ", 5, 0),
+		provider.TextResponse("the answer", 10, 10),
+	)
+	emit, evs := collect()
+	req := provider.Request{Messages: []provider.Message{provider.UserText("go")}}
+	resp, usage, err := RunToolLoop(context.Background(), solver, req, NewToolRegistry(), 4, emit, "solo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text() != "the answer" || usage.InputTokens != 15 {
+		t.Fatalf("resp=%q usage=%+v", resp.Text(), usage)
+	}
+	msgs := solver.Requests[1].Messages
+	if last := msgs[len(msgs)-1]; last.Role != "user" || !strings.Contains(last.Content[0].Text, "no output") {
+		t.Fatalf("retry should carry the nudge, got %+v", last)
+	}
+	if len(kinds(*evs, events.KindError)) != 1 {
+		t.Fatal("the retry should be reported as an event")
+	}
+
+	blank := provider.NewFake("solver", "anthropic", provider.TextResponse("", 5, 0))
+	_, _, err = RunToolLoop(context.Background(), blank, req, NewToolRegistry(), 4, emit, "solo")
+	if !errors.Is(err, ErrEmptyOutput) || len(blank.Requests) != 2 {
+		t.Fatalf("want ErrEmptyOutput after one retry, got %v (%d calls)", err, len(blank.Requests))
+	}
+}
@@ -2,23 +2,71 @@ package thinking
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/stukennedy/kyotee/internal/events"
 	"github.com/stukennedy/kyotee/internal/provider"
+	"github.com/stukennedy/kyotee/internal/redact"
 )
 
+// ErrToolLoopStuck fails a tool loop whose model keeps re-issuing the same
+// tool calls after being told to change strategy.
+var ErrToolLoopStuck = errors.New("stuck in a loop")
+
+// ErrEmptyOutput fails a tool loop whose model answers with no text (and no
+// tool calls) twice in a row.
+var ErrEmptyOutput = errors.New("model returned empty output")
+
+// emptyNudge follows a blank final answer; one retry usually recovers it.
+const emptyNudge = "You returned no output. Please answer the task now."
+
+// repeatNudge replaces the results of a round that repeats an earlier one
+// reg.RepeatLimit times; the tools are not re-run since their output would
+// be what the model has already seen.
+const repeatNudge = "You are repeating yourself: this exact tool call has already been made with the same input and its result is above. Change strategy — use the results you already have, try a materially different call, or answer now."
+
 // RunToolLoop executes a standard tool-use loop: call the model, execute any
 // requested tools, feed results back, repeat until the model stops requesting
 // tools or maxCalls tool executions have run. On hitting the cap it forces a
-// final tool-free answer, so the loop always terminates with text.
-// Returns the final response and the aggregate usage across all calls.
+// final tool-free answer, so the loop always terminates with text. A round
+// of tool calls identical to an earlier one is answered with a "change
+// strategy" nudge once it has recurred reg.RepeatLimit times; recurring
+// again after that fails with ErrToolLoopStuck. Results of read-only tools
+// are cached for the life of the loop, so re-reading the same file or
+// re-running the same search costs nothing until a mutating tool runs; a
+// mutating tool also resets the repeat count, since earlier calls may now
+// see new state.
+// Response text is emitted as model.delta events while it streams.
+// A blank final answer is retried once with emptyNudge before failing with
+// ErrEmptyOutput. Returns the final response and the aggregate usage across
+// all calls.
 func RunToolLoop(ctx context.Context, p provider.Provider, req provider.Request, reg *ToolRegistry, maxCalls int, emit events.Emitter, stage string) (provider.Response, provider.Usage, error) {
 	if maxCalls <= 0 {
 		maxCalls = 5
 	}
 	var total provider.Usage
 	callsUsed := 0
+	seen := map[string]int{}     // round signature → occurrences
+	cache := map[string]string{} // read-only call → output
+	limit := reg.repeatLimit()
+	nudgedEmpty := false
+	if req.Stream == nil {
+		// Stream text as it arrives so the TUI and `ask --wait` are not
+		// blank until the whole response lands.
+		req.Stream = func(d provider.Delta) {
+			if d.Type == "text" && d.Text != "" {
+				emit(events.Event{
+					Kind: events.KindModelDelta, Stage: stage, Actor: p.Name(),
+					Payload: map[string]any{"text": d.Text},
+				})
+			}
+		}
+	}
 
 	for {
 		resp, err := p.Generate(ctx, req)
@@ -32,12 +80,33 @@ func RunToolLoop(ctx context.Context, p provider.Provider, req provider.Request,
 		// cap-forced ToolChoice="none" round (even if the model still tried
 		// to call a tool) — the loop must never spin.
 		if len(calls) == 0 || len(req.Tools) == 0 || req.ToolChoice == "none" {
-			return resp, total, nil
+			if strings.TrimSpace(resp.Text()) != "" {
+				return resp, total, nil
+			}
+			if nudgedEmpty {
+				return provider.Response{}, total, fmt.Errorf("%s: %w after a retry", p.Name(), ErrEmptyOutput)
+			}
+			nudgedEmpty = true
+			emit(events.Event{
+				Kind: events.KindError, Stage: stage, Actor: p.Name(),
+				Payload: map[string]any{"message": p.Name() + " returned empty output; retrying once", "stage": stage},
+			})
+			req.Messages = append(req.Messages, provider.UserText(emptyNudge))
+			continue
 		}
 
+		sig := callSignature(calls)
+		seen[sig]++
+		if seen[sig] > limit {
+			return provider.Response{}, total, fmt.Errorf("%s: %w: same tool calls issued %d times (%s)",
+				p.Name(), ErrToolLoopStuck, seen[sig], calls[0].Name)
+		}
+		repeating := seen[sig] == limit
+
 		// Record the assistant turn, then execute each requested tool.
 		req.Messages = append(req.Messages, provider.Message{Role: "assistant", Content: resp.Content})
 		var results []provider.Block
+		mutated := false
 		for _, call := range calls {
 			inputJSON, _ := json.Marshal(call.Input)
 			emit(events.Event{
@@ -46,16 +115,38 @@ func RunToolLoop(ctx context.Context, p provider.Provider, req provider.Request,
 			})
 
 			var output string
-			var isErr bool
-			if callsUsed >= maxCalls {
+			var isErr, cached bool
+			if repeating {
+				// A wasted round still spends the cap, so a model that
+				// ignores the nudge is also on course for a forced answer.
+				output, isErr = repeatNudge, true
+				callsUsed++
+			} else if callsUsed >= maxCalls {
 				output = "Tool call limit reached. Answer now with the information you already have."
 				isErr = true
 			} else if tool, ok := reg.Get(call.Name); ok {
-				out, execErr := tool.Exec(ctx, call.Input)
-				if execErr != nil {
-					output, isErr = "tool error: "+execErr.Error(), true
+				key := call.Name + "\x00" + string(inputJSON)
+				if out, hit := cache[key]; hit {
+					output, cached = out, true
 				} else {
-					output = out
+					out, execErr := reg.exec(ctx, tool, call.Input)
+					if execErr != nil {
+						output, isErr = "tool error: "+execErr.Error(), true
+					} else {
+						output = out
+					}
+					// Errors echo paths, URLs and content too, so both
+					// branches are masked.
+					if reg.Redact {
+						output = redact.Secrets(output)
+					}
+					switch {
+					case !readOnly(tool):
+						clear(cache)
+						mutated = true
+					case execErr == nil:
+						cache[key] = output
+					}
 				}
 				callsUsed++
 			} else {
@@ -64,13 +155,21 @@ func RunToolLoop(ctx context.Context, p provider.Provider, req provider.Request,
 
 			emit(events.Event{
 				Kind: events.KindToolResult, Stage: stage, Actor: p.Name(),
-				Payload: map[string]any{"name": call.Name, "output": truncateStr(output, 2000), "is_error": isErr},
+				Payload: map[string]any{"name": call.Name, "output": truncateStr(output, 2000), "is_error": isErr, "cached": cached},
 			})
 			results = append(results, provider.Block{Type: "tool_result", ToolResult: &provider.ToolResult{
 				CallID: call.ID, Content: output, IsError: isErr,
 			}})
 		}
 		req.Messages = append(req.Messages, provider.Message{Role: "tool", Content: results})
+		if mutated {
+			// The world changed, so re-issuing an earlier call is no longer
+			// a repeat. This round keeps its count: the same edit over and
+			// over is still a loop.
+			n := seen[sig]
+			clear(seen)
+			seen[sig] = n
+		}
 
 		if callsUsed >= maxCalls {
 			// Force a final completion with no further tool use. Tools stay
@@ -82,6 +181,17 @@ func RunToolLoop(ctx context.Context, p provider.Provider, req provider.Request,
 	}
 }
 
+// callSignature fingerprints a round of tool calls by name and input;
+// call IDs differ between rounds and are deliberately excluded.
+func callSignature(calls []*provider.ToolCall) string {
+	h := sha256.New()
+	for _, c := range calls {
+		in, _ := json.Marshal(c.Input) // map keys marshal sorted → stable
+		fmt.Fprintf(h, "%s\x00%s\x00", c.Name, in)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func truncateStr(s string, n int) string {
 	if len(s) <= n {
 		return s
@@ -2,9 +2,11 @@ package thinking
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/stukennedy/kyotee/internal/provider"
 )
@@ -15,9 +17,108 @@ type Tool interface {
 	Exec(ctx context.Context, input map[string]any) (string, error)
 }
 
+// ReadOnlyTool is implemented by tools with no side effects (file_read,
+// web_search). RunToolLoop reuses their results for identical calls within
+// one loop; a call to any other tool clears that cache, since it may have
+// changed what a read would return.
+type ReadOnlyTool interface {
+	ReadOnly() bool
+}
+
+func readOnly(t Tool) bool {
+	ro, ok := t.(ReadOnlyTool)
+	return ok && ro.ReadOnly()
+}
+
+// NetworkTool is implemented by tools that reach the network (web_search).
+// ToolRegistry.ForbidNetwork refuses to run them.
+type NetworkTool interface {
+	Network() bool
+}
+
+func usesNetwork(t Tool) bool {
+	nt, ok := t.(NetworkTool)
+	return ok && nt.Network()
+}
+
 // ToolRegistry holds the provider-agnostic tools available to solvers.
 type ToolRegistry struct {
 	m map[string]Tool
+	// RepeatLimit is how many times RunToolLoop tolerates the model issuing
+	// the identical round of tool calls before it intervenes
+	// (defaults.tool_repeat_limit; <= 0 → DefaultRepeatLimit).
+	RepeatLimit int
+	// Timeout caps one tool execution (defaults.tool_timeout_seconds;
+	// <= 0 → DefaultToolTimeout).
+	Timeout time.Duration
+	// Redact masks credentials in tool output before it enters the prompt,
+	// the tool.result event, or the persisted transcript
+	// (policies.redact_secrets).
+	Redact bool
+	// ForbidNetwork refuses every NetworkTool call with a policy error the
+	// model reads as the tool result (policies.forbid_network). The tools
+	// stay advertised so the model learns why it cannot look things up.
+	ForbidNetwork bool
+}
+
+const (
+	// DefaultRepeatLimit applies when the registry carries no RepeatLimit.
+	DefaultRepeatLimit = 3
+	// DefaultToolTimeout applies when the registry carries no Timeout.
+	DefaultToolTimeout = 2 * time.Minute
+)
+
+func (r *ToolRegistry) repeatLimit() int {
+	if r == nil || r.RepeatLimit <= 0 {
+		return DefaultRepeatLimit
+	}
+	return r.RepeatLimit
+}
+
+func (r *ToolRegistry) timeout() time.Duration {
+	if r == nil || r.Timeout <= 0 {
+		return DefaultToolTimeout
+	}
+	return r.Timeout
+}
+
+// exec runs one tool under the registry timeout, reported as an error the
+// model can read and react to. A read-only tool runs on its own goroutine so
+// one that ignores ctx still cannot hang the loop. Any other tool is waited
+// for: it must honour ctx, and abandoning it could let a write land after
+// the model has been told the call failed.
+func (r *ToolRegistry) exec(ctx context.Context, t Tool, input map[string]any) (string, error) {
+	if r != nil && r.ForbidNetwork && usesNetwork(t) {
+		return "", fmt.Errorf("%s: blocked by policy (policies.forbid_network): network access is disabled; answer from the material you have and say what could not be verified", t.Def().Name)
+	}
+	d := r.timeout()
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	if !readOnly(t) {
+		out, err := t.Exec(ctx, input)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s timed out after %s", t.Def().Name, d)
+		}
+		return out, err
+	}
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := t.Exec(ctx, input)
+		done <- result{out, err}
+	}()
+	select {
+	case res := <-done:
+		return res.out, res.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s timed out after %s", t.Def().Name, d)
+		}
+		return "", ctx.Err()
+	}
 }
 
 func NewToolRegistry(tools ...Tool) *ToolRegistry {
@@ -67,10 +168,13 @@ func (r *ToolRegistry) DefsFor(names []string) []provider.ToolDef {
 type FuncTool struct {
 	Definition provider.ToolDef
 	Fn         func(ctx context.Context, input map[string]any) (string, error)
+	NoEffects  bool // side-effect free: results may be cached (ReadOnlyTool)
 }
 
 func (f *FuncTool) Def() provider.ToolDef { return f.Definition }
 
+func (f *FuncTool) ReadOnly() bool { return f.NoEffects }
+
 func (f *FuncTool) Exec(ctx context.Context, input map[string]any) (string, error) {
 	if f.Fn == nil {
 		return "", fmt.Errorf("tool %s has no implementation", f.Definition.Name)
@@ -0,0 +1,58 @@
+package thinking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/stukennedy/kyotee/internal/budget"
+	"github.com/stukennedy/kyotee/internal/events"
+	"github.com/stukennedy/kyotee/internal/jsonx"
+	"github.com/stukennedy/kyotee/internal/pipeline"
+	"github.com/stukennedy/kyotee/internal/provider"
+)
+
+// ErrUnparseableVerdict marks a verdict that still failed to parse once
+// its retries were spent.
+var ErrUnparseableVerdict = errors.New("parse failure")
+
+// Verdict asks p for a strict-JSON verdict and decodes it into v. An
+// unparseable reply is re-prompted up to retries times (defaults.
+// verdict_retries) with the parse error fed back, while st's budget remains.
+// Every attempt is recorded as a turn of stage/role. The returned error is
+// either p's call error or wraps ErrUnparseableVerdict.
+func Verdict(ctx context.Context, p provider.Provider, req provider.Request, v any, retries int, st *pipeline.State, emit events.Emitter, stage, role string) error {
+	for attempt := 0; ; attempt++ {
+		resp, err := p.Generate(ctx, req)
+		if err != nil {
+			return err
+		}
+		st.AddTurn(stage, role, resp.Text(), resp.Usage)
+		budget.CheckWarn(&st.Budget, emit)
+
+		perr := jsonx.Parse(resp.Text(), v)
+		if perr == nil {
+			return nil
+		}
+		if attempt >= retries || st.Budget.Exhausted() {
+			return fmt.Errorf("%s %w: %v", role, ErrUnparseableVerdict, perr)
+		}
+		// Show the model its own output (as its turn) and what was wrong
+		// with it; a second try with the error in hand usually yields
+		// clean JSON.
+		emit(events.Event{
+			Kind: events.KindError, Stage: stage, Actor: p.Name(),
+			Payload: map[string]any{"level": "warn", "message": role + " parse failure: " + perr.Error() + " — retrying"},
+		})
+		req.Messages = append(req.Messages,
+			provider.Message{Role: "assistant", Content: resp.Content},
+			provider.UserText(verdictFeedback(perr)))
+	}
+}
+
+// verdictFeedback asks for a corrected verdict, quoting the parse error; the
+// offending output is already in the conversation as the assistant turn.
+func verdictFeedback(err error) string {
+	return "Your reply above could not be parsed as the required JSON (" + err.Error() +
+		"). Respond again with the JSON object ONLY, no prose, no fences."
+}
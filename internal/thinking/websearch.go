@@ -40,6 +40,10 @@ func (w *WebSearch) Def() provider.ToolDef {
 	}
 }
 
+func (w *WebSearch) ReadOnly() bool { return true }
+
+func (w *WebSearch) Network() bool { return true }
+
 var (
 	ddgResultRe  = regexp.MustCompile(`(?s)<a[^>]+class="result__a"[^>]*href="([^"]+)"[^>]*>(.*?)</a>`)
 	ddgSnippetRe = regexp.MustCompile(`(?s)<a[^>]+class="result__snippet"[^>]*>(.*?)</a>`)
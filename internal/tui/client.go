@@ -192,6 +192,20 @@ func (c *Client) ResumeCmd(taskID string) app.Cmd {
 	}
 }
 
+func (c *Client) CancelCmd(taskID string) app.Cmd {
+	return func() app.Msg {
+		resp, err := c.HTTP.Post(c.BaseURL+"/v1/tasks/"+taskID+"/cancel", "application/json", nil)
+		if err != nil {
+			return CancelledMsg{Err: err}
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			return CancelledMsg{Err: apiError(resp)}
+		}
+		return CancelledMsg{TaskID: taskID}
+	}
+}
+
 func apiError(resp *http.Response) error {
 	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
 	var body struct {
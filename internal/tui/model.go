@@ -5,8 +5,10 @@ package tui
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -49,15 +51,22 @@ type (
 	}
 	// HealthMsg reports engine reachability from the background poll.
 	HealthMsg struct{ Up bool }
+	// CancelledMsg is the POST cancel result.
+	CancelledMsg struct {
+		TaskID string
+		Err    error
+	}
 )
 
 // inputMode drives the vim-style modal input: NORMAL treats letters as
-// commands, INSERT sends every key into the prompt.
+// commands, INSERT sends every key into the prompt, SEARCH into the event-log
+// filter.
 type inputMode int
 
 const (
 	modeInsert inputMode = iota // default: typing a prompt is the primary action
 	modeNormal
+	modeSearch
 )
 
 type TaskSummary struct {
@@ -118,20 +127,41 @@ type Model struct {
 	Council   map[string]*MemberView
 	Members   []string // stable pane order
 	Consensus string
+	Round     int // latest twobrain/council round seen
+	RoundsMax int // round cap for the running debate (0 = none)
 	Synthesis string
+	Partial   string // solo answer text streamed so far, until task.final
 	Final     string
 	SpentUSD  float64
 	LimitUSD  float64
 	WarnPct   float64
-	Log       []string
+	Log       []string       // most recent LogLines event-log lines, oldest first
+	errMsg    string         // last engine error for this task, for RunLog
 	seen      map[int64]bool // Seq de-dup across reconnects
 
+	Verbose bool  // tool inputs rendered in full-ish (kyotee -v)
+	Theme   Theme // palette, fixed at launch
+
+	// Event-log sizing: LogLines caps what is kept in memory (a chatty
+	// council run emits thousands of events), LogView is how many are shown.
+	LogLines int // 0 = DefaultLogLines
+	LogView  int // 0 = DefaultLogView
+
+	// Stall watchdog: a streaming task that emits nothing for StallAfter is
+	// flagged in the status line, with x offered to cancel it.
+	StallAfter  time.Duration // 0 = DefaultStallAfter; < 0 = off
+	lastEventAt time.Time     // last event (or stream start) for TaskID
+	stalled     bool          // the stall warning is showing
+
 	Connected bool // a per-task SSE stream is currently open
 	EngineUp  bool // engine reachable, per the /v1/healthz poll
 	Status    string
 
 	Mode        inputMode
-	pollStarted bool // guards one-time bootstrap of the health poll
+	Search      component.TextInput // SEARCH-mode query editor
+	Filter      string              // event-log filter; "" = show everything
+	hit         int                 // selected match, an index into LogMatches
+	pollStarted bool                // guards one-time bootstrap of the health poll
 
 	// Conversation threading: consecutive prompts continue the same thread
 	// until the user starts a new one (NORMAL 'n').
@@ -154,9 +184,59 @@ type Model struct {
 	cancelStream context.CancelFunc // stops the previous task's SSE stream
 }
 
+// DefaultStallAfter is how long a streaming task may stay silent before the
+// TUI says so. Slow-mode solvers legitimately think for a while; a minute of
+// nothing at all is worth surfacing.
+const DefaultStallAfter = 60 * time.Second
+
+// Event-log defaults: lines retained per task and lines displayed.
+const (
+	DefaultLogLines = 500
+	DefaultLogView  = 6
+)
+
+func (m *Model) logLines() int {
+	if m.LogLines > 0 {
+		return m.LogLines
+	}
+	return DefaultLogLines
+}
+
+func (m *Model) logView() int {
+	if m.LogView > 0 {
+		return m.LogView
+	}
+	return DefaultLogView
+}
+
+// Stalled reports how long the current task has been silent, or 0 when it
+// isn't: no open stream, already answered, or still inside the allowance.
+func (m *Model) Stalled(now time.Time) time.Duration {
+	after := m.StallAfter
+	if after == 0 {
+		after = DefaultStallAfter
+	}
+	if after < 0 || !m.Connected || m.Final != "" || m.lastEventAt.IsZero() {
+		return 0
+	}
+	if quiet := now.Sub(m.lastEventAt); quiet >= after {
+		return quiet
+	}
+	return 0
+}
+
+// checkStall runs on each health-poll tick, the TUI's only clock.
+func (m *Model) checkStall(now time.Time) {
+	if quiet := m.Stalled(now); quiet > 0 {
+		m.stalled = true
+		m.Status = fmt.Sprintf("no engine events for %ds — task may be stuck; x to cancel", int(quiet.Seconds()))
+	}
+}
+
 // startStream cancels any previous task's stream and returns a Sub for the
 // new one.
 func (m *Model) startStream(taskID string) app.Sub {
+	m.lastEventAt, m.stalled = time.Now(), false
 	if m.cancelStream != nil {
 		m.cancelStream()
 	}
@@ -173,6 +253,7 @@ func NewModel(client *Client) *Model {
 		seen:    map[int64]bool{},
 		Status:  "connecting…",
 		Mode:    modeInsert,
+		Theme:   LoadTheme(DefaultTheme),
 	}
 	m.Input.Focused = true
 	return m
@@ -182,15 +263,18 @@ func NewModel(client *Client) *Model {
 func (m *Model) reset(taskID string) {
 	m.TaskID = taskID
 	m.Class = nil
-	m.Strategy, m.Stage, m.ThinkMode, m.ToolCheck, m.Consensus, m.Synthesis, m.Final = "", "", "", "", "", "", ""
+	m.Strategy, m.Stage, m.ThinkMode, m.ToolCheck, m.Consensus, m.Synthesis, m.Partial, m.Final = "", "", "", "", "", "", "", ""
 	m.Pipeline = nil
 	m.ToolCalls = nil
 	m.Brains = nil
 	m.Referee = ""
 	m.Council = map[string]*MemberView{}
 	m.Members = nil
+	m.Round, m.RoundsMax = 0, 0
 	m.SpentUSD, m.LimitUSD, m.WarnPct = 0, 0, 0
 	m.Log = nil
+	m.errMsg = ""
+	m.hit = -1
 	m.seen = map[int64]bool{}
 }
 
@@ -214,6 +298,9 @@ func update(m *Model, msg app.Msg) app.UpdateResult[*Model] {
 	case app.PasteMsg:
 		if m.Active == overlayConfig {
 			m.ConfigInput = m.ConfigInput.Paste(msg.Text)
+		} else if m.Mode == modeSearch {
+			m.Search = m.Search.Paste(msg.Text)
+			m.setFilter(m.Search.Value)
 		} else {
 			m.Input = m.Input.Paste(msg.Text)
 		}
@@ -234,6 +321,22 @@ func update(m *Model, msg app.Msg) app.UpdateResult[*Model] {
 		return app.NoCmd(m)
 	case HealthMsg:
 		m.EngineUp = msg.Up
+		m.checkStall(time.Now())
+		return app.NoCmd(m)
+	case CancelledMsg:
+		if msg.Err != nil {
+			m.Status = "cancel failed: " + msg.Err.Error()
+			return app.NoCmd(m)
+		}
+		m.stalled = false
+		m.Status = "cancelling " + msg.TaskID + "…"
+		return app.NoCmd(m)
+	case RunLogWrittenMsg:
+		if msg.Err != nil {
+			m.Status = "log write failed: " + msg.Err.Error()
+			return app.NoCmd(m)
+		}
+		m.Status = "run log written to " + msg.Path
 		return app.NoCmd(m)
 
 	case TaskCreatedMsg:
@@ -305,12 +408,66 @@ func (m *Model) handleKey(k input.Key) app.UpdateResult[*Model] {
 		return m.handleOverrideKey(k)
 	}
 
-	if m.Mode == modeNormal {
+	switch m.Mode {
+	case modeNormal:
 		return m.handleNormalKey(k)
+	case modeSearch:
+		return m.handleSearchKey(k)
 	}
 	return m.handleInsertKey(k)
 }
 
+// handleSearchKey: keys edit the event-log filter, which applies as you
+// type. Enter keeps it (n/N then step through matches); Escape clears it.
+func (m *Model) handleSearchKey(k input.Key) app.UpdateResult[*Model] {
+	switch k.Type {
+	case input.Escape:
+		m.setFilter("")
+		m.Mode = modeNormal
+		return app.NoCmd(m)
+	case input.Enter:
+		m.Mode = modeNormal
+		if m.Filter != "" {
+			m.Status = fmt.Sprintf("%d matches for /%s · n/N: next/prev · Esc: clear", len(LogMatches(m.Log, m.Filter)), m.Filter)
+		}
+		return app.NoCmd(m)
+	}
+	m.Search = m.Search.Update(k)
+	m.setFilter(m.Search.Value)
+	return app.NoCmd(m)
+}
+
+// setFilter applies an event-log query and selects its latest match.
+func (m *Model) setFilter(q string) {
+	m.Filter = strings.TrimSpace(q)
+	m.hit = len(LogMatches(m.Log, m.Filter)) - 1
+}
+
+// stepHit moves the selected match by delta, wrapping at either end.
+func (m *Model) stepHit(delta int) {
+	n := len(LogMatches(m.Log, m.Filter))
+	if n == 0 {
+		return
+	}
+	m.hit = ((m.hit+delta)%n + n) % n
+}
+
+// LogMatches returns the indices of log lines containing query,
+// case-insensitively. An empty query matches nothing.
+func LogMatches(lines []string, query string) []int {
+	if query == "" {
+		return nil
+	}
+	q := strings.ToLower(query)
+	var out []int
+	for i, l := range lines {
+		if strings.Contains(strings.ToLower(l), q) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
 // handleInsertKey: every key edits the prompt. Enter submits; Escape drops to
 // NORMAL so the command letters become available.
 func (m *Model) handleInsertKey(k input.Key) app.UpdateResult[*Model] {
@@ -331,12 +488,28 @@ func (m *Model) handleNormalKey(k input.Key) app.UpdateResult[*Model] {
 	switch k.Type {
 	case input.Enter:
 		return m.submit()
+	case input.Escape:
+		if m.Filter != "" {
+			m.setFilter("")
+			m.Status = "filter cleared"
+		}
 	case input.RuneKey:
 		switch k.Rune {
 		case 'i', 'a':
 			m.Mode = modeInsert
+		case '/':
+			m.Mode = modeSearch
+			m.Search = component.NewTextInput("filter the event log…")
+			m.Search = m.Search.Paste(m.Filter)
+			m.Search.Focused = true
 		case 'n':
+			if m.Filter != "" {
+				m.stepHit(1) // while filtering, n/N step through matches
+				break
+			}
 			m.newConversation()
+		case 'N':
+			m.stepHit(-1)
 		case 'q':
 			return app.Quit(m)
 		case 'c':
@@ -347,6 +520,16 @@ func (m *Model) handleNormalKey(k input.Key) app.UpdateResult[*Model] {
 			return app.WithCmd(m, m.Client.ListTasksCmd())
 		case 'o':
 			m.Active = overlayOverride
+		case 'x':
+			if m.TaskID != "" && m.Connected && m.Final == "" {
+				m.Status = "cancelling…"
+				return app.WithCmd(m, m.Client.CancelCmd(m.TaskID))
+			}
+		case 'w':
+			if m.TaskID != "" {
+				m.Status = "writing run log…"
+				return app.WithCmd(m, WriteRunLogCmd(LastRunLog, m.RunLog()))
+			}
 		}
 	}
 	return app.NoCmd(m)
@@ -454,6 +637,19 @@ func (m *Model) applyEvent(ev events.Event) {
 		return // stale stream or reconnect duplicate
 	}
 	m.seen[ev.Seq] = true
+	m.lastEventAt = time.Now()
+	if m.stalled {
+		m.stalled = false
+		m.Status = "task " + m.TaskID
+	}
+	if ev.Kind == events.KindModelDelta {
+		// Streamed text shows in the answer pane, not one log line per chunk.
+		if ev.Stage == "solo" {
+			text, _ := ev.Payload["text"].(string)
+			m.Partial += text
+		}
+		return
+	}
 	m.logEvent(ev)
 
 	p := ev.Payload
@@ -496,9 +692,14 @@ func (m *Model) applyEvent(ev events.Event) {
 		}
 		m.ToolCheck = verdict
 	case events.KindToolCall:
+		m.Partial = "" // preamble to a tool call; the answer comes after
 		name, _ := p["name"].(string)
 		in, _ := p["input"].(string)
-		m.ToolCalls = append(m.ToolCalls, fmt.Sprintf("%s %s", name, truncate(in, 40)))
+		if m.Verbose {
+			m.ToolCalls = append(m.ToolCalls, name+" "+FormatToolInput(in, 100))
+		} else {
+			m.ToolCalls = append(m.ToolCalls, fmt.Sprintf("%s %s", name, truncate(in, 40)))
+		}
 	case events.KindBrainTurn:
 		role, _ := p["role"].(string)
 		text, _ := p["text"].(string)
@@ -507,6 +708,7 @@ func (m *Model) applyEvent(ev events.Event) {
 			m.Referee = text
 		} else {
 			m.Brains = append(m.Brains, BrainTurn{Role: role, Round: round, Text: text})
+			m.Round, m.RoundsMax = round, intFrom(p["rounds_max"])
 		}
 	case events.KindCouncilOpening, events.KindCouncilRebuttal:
 		model, _ := p["model"].(string)
@@ -518,6 +720,9 @@ func (m *Model) applyEvent(ev events.Event) {
 			mv.Position = text
 		}
 		mv.Round = intFrom(p["round"])
+		if mv.Round > 0 {
+			m.Round, m.RoundsMax = mv.Round, intFrom(p["rounds_max"])
+		}
 	case events.KindCouncilVote:
 		model, _ := p["model"].(string)
 		mv := m.memberView(model)
@@ -528,12 +733,14 @@ func (m *Model) applyEvent(ev events.Event) {
 	case events.KindCouncilConsensus:
 		reached, _ := p["reached"].(bool)
 		method, _ := p["method"].(string)
-		rounds := intFrom(p["rounds_used"])
+		rounds := events.RoundProgress(intFrom(p["rounds_used"]), intFrom(p["rounds_max"]))
 		if reached {
-			m.Consensus = fmt.Sprintf("✓ consensus (%s, %d rounds)", method, rounds)
+			m.Consensus = fmt.Sprintf("✓ consensus (%s, %s rounds)", method, rounds)
 		} else {
-			m.Consensus = fmt.Sprintf("… no consensus yet (%s, %d rounds)", method, rounds)
+			m.Consensus = fmt.Sprintf("… no consensus yet (%s, %s rounds)", method, rounds)
 		}
+	case events.KindConfigChanged:
+		m.Status, _ = p["message"].(string)
 	case events.KindBudgetWarn:
 		if pct, ok := p["pct"].(float64); ok && pct > m.WarnPct {
 			m.WarnPct = pct
@@ -546,6 +753,7 @@ func (m *Model) applyEvent(ev events.Event) {
 		}
 	case events.KindTaskFinal:
 		m.Final, _ = p["text"].(string)
+		m.Partial = ""
 		if cost, ok := p["total_cost_usd"].(float64); ok {
 			m.SpentUSD = cost
 		}
@@ -559,6 +767,7 @@ func (m *Model) applyEvent(ev events.Event) {
 		}
 	case events.KindError:
 		if msg, ok := p["message"].(string); ok {
+			m.errMsg = msg
 			m.Status = "engine: " + truncate(msg, 80)
 		}
 	}
@@ -578,10 +787,18 @@ func (m *Model) memberView(model string) *MemberView {
 func (m *Model) logEvent(ev events.Event) {
 	ts := time.UnixMilli(ev.TS).Format("15:04:05")
 	line := fmt.Sprintf("%s %-18s %s", ts, ev.Kind, ev.Actor)
-	m.Log = append(m.Log, line)
-	if len(m.Log) > 500 {
-		m.Log = m.Log[len(m.Log)-500:]
+	if m.Verbose && ev.Kind == events.KindToolCall {
+		name, _ := ev.Payload["name"].(string)
+		in, _ := ev.Payload["input"].(string)
+		line += " " + name + " " + FormatToolInput(in, 80)
+	}
+	// Drop the oldest line in place once full, so the backing array never
+	// outgrows the cap.
+	if n := m.logLines(); len(m.Log) >= n {
+		drop := len(m.Log) - n + 1
+		m.Log = append(m.Log[:0], m.Log[drop:]...)
 	}
+	m.Log = append(m.Log, line)
 }
 
 func intFrom(v any) int {
@@ -594,9 +811,41 @@ func intFrom(v any) int {
 	return 0
 }
 
+// FormatToolInput renders a tool.call input (a JSON object) as sorted
+// key=value pairs on one line — `path=main.go old_string="a := 1"` — with
+// long values clipped so one call never floods the pane, and the whole line
+// capped at max. Non-object input falls back to the raw text.
+func FormatToolInput(raw string, max int) string {
+	var in map[string]any
+	if err := json.Unmarshal([]byte(raw), &in); err != nil || len(in) == 0 {
+		return truncate(raw, max)
+	}
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		var v string
+		if s, ok := in[k].(string); ok {
+			v = truncate(strings.ReplaceAll(s, "\n", "⏎"), 40)
+			if s == "" || strings.ContainsAny(v, " \t\"=") {
+				v = strconv.Quote(v)
+			}
+		} else {
+			b, _ := json.Marshal(in[k])
+			v = truncate(string(b), 40)
+		}
+		parts = append(parts, k+"="+v)
+	}
+	return truncate(strings.Join(parts, " "), max)
+}
+
+// truncate caps s at n runes, never splitting a multi-byte character.
 func truncate(s string, n int) string {
-	if len(s) <= n {
-		return s
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
 	}
-	return s[:n] + "…"
+	return s
 }
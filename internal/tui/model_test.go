@@ -1,12 +1,19 @@
 package tui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/stukennedy/tooey/app"
 	"github.com/stukennedy/tooey/input"
 	"github.com/stukennedy/tooey/tooeytest"
+
+	"github.com/stukennedy/kyotee/internal/events"
 )
 
 func runeKey(r rune) app.Msg { return app.KeyMsg{Key: input.Key{Type: input.RuneKey, Rune: r}} }
@@ -101,3 +108,199 @@ func TestSoloAnswerRendersMarkdown(t *testing.T) {
 		}
 	}
 }
+
+// Streamed solo text shows in the answer pane as it arrives, stays out of
+// the event log, and gives way to the final answer.
+func TestSoloStreamsPartialAnswer(t *testing.T) {
+	m := NewModel(NewClient("http://localhost:0"))
+	m.reset("t1")
+	m.lastPrompt = "hi"
+	for i, chunk := range []string{"partial ", "answer"} {
+		m.applyEvent(events.Event{TaskID: "t1", Seq: int64(i), Kind: events.KindModelDelta, Stage: "solo", Payload: map[string]any{"text": chunk}})
+	}
+	if frame := tooeytest.RenderText(m.viewSolo(), 120, 12); !strings.Contains(frame, "partial answer") {
+		t.Fatalf("streamed text not shown:\n%s", frame)
+	}
+	if len(m.Log) != 0 {
+		t.Fatalf("deltas logged: %v", m.Log)
+	}
+	m.applyEvent(events.Event{TaskID: "t1", Seq: 2, Kind: events.KindTaskFinal, Payload: map[string]any{"text": "done"}})
+	if m.Partial != "" || m.Final != "done" {
+		t.Fatalf("partial %q final %q after task.final", m.Partial, m.Final)
+	}
+}
+
+func TestFormatToolInput(t *testing.T) {
+	cases := []struct {
+		raw, want string
+	}{
+		{`{"query":"uk prime minister"}`, `query="uk prime minister"`},
+		{`{"path":"main.go","old_string":"a := 1\nb := 2","new_string":"a := 3"}`,
+			`new_string="a := 3" old_string="a := 1⏎b := 2" path=main.go`},
+		{`{"path":"x","limit":5}`, `limit=5 path=x`},
+		{`not json`, `not json`},
+	}
+	for _, c := range cases {
+		if got := FormatToolInput(c.raw, 200); got != c.want {
+			t.Fatalf("FormatToolInput(%s) = %s, want %s", c.raw, got, c.want)
+		}
+	}
+	if got := FormatToolInput(`{"query":"`+strings.Repeat("x", 300)+`"}`, 60); len(got) > 60+len("…") {
+		t.Fatalf("line not capped: %d bytes", len(got))
+	}
+	// A newline landing on the 40-rune value cut must not be split mid-⏎.
+	got := FormatToolInput(`{"s":"`+strings.Repeat("a", 39)+`\nbc"}`, 200)
+	if want := "s=" + strings.Repeat("a", 39) + "⏎…"; got != want || !utf8.ValidString(got) {
+		t.Fatalf("FormatToolInput cut mid-rune: %q, want %q", got, want)
+	}
+}
+
+// The stall watchdog fires only after StallAfter of silence on an open
+// stream, and any event clears it.
+func TestStallWatchdog(t *testing.T) {
+	m := NewModel(NewClient("http://localhost:0"))
+	m.StallAfter = 30 * time.Second
+	m.reset("t1")
+	m.Connected = true
+	start := time.Now()
+	m.lastEventAt = start
+
+	if d := m.Stalled(start.Add(29 * time.Second)); d != 0 {
+		t.Fatalf("fired early: %v", d)
+	}
+	m.checkStall(start.Add(45 * time.Second))
+	if !strings.Contains(m.Status, "45s") || !strings.Contains(m.Status, "x to cancel") {
+		t.Fatalf("stall not surfaced: %q", m.Status)
+	}
+
+	Update(m, SSEMsg{Event: events.Event{TaskID: "t1", Seq: 1, Kind: events.KindStageStart}})
+	if m.stalled || strings.Contains(m.Status, "stuck") {
+		t.Fatalf("event did not clear the stall: %q", m.Status)
+	}
+	if d := m.Stalled(time.Now().Add(10 * time.Second)); d != 0 {
+		t.Fatalf("silence counted from the old event: %v", d)
+	}
+
+	// Answered, disconnected, or disabled: never stalled.
+	m.Final = "done"
+	if m.Stalled(start.Add(time.Hour)) != 0 {
+		t.Fatal("answered task flagged as stalled")
+	}
+	m.Final, m.Connected = "", false
+	if m.Stalled(start.Add(time.Hour)) != 0 {
+		t.Fatal("closed stream flagged as stalled")
+	}
+	m.Connected, m.StallAfter = true, -1
+	if m.Stalled(start.Add(time.Hour)) != 0 {
+		t.Fatal("disabled watchdog fired")
+	}
+}
+
+// '/' filters the event log as you type; n/N step through matches while a
+// filter is active, and Escape clears it.
+func TestLogSearch(t *testing.T) {
+	m := NewModel(NewClient("http://localhost:0"))
+	m.Log = []string{
+		"10:00:00 task.received      engine",
+		"10:00:01 tool.call          solver web_search",
+		"10:00:02 tool.result        solver",
+		"10:00:03 tool.call          solver file_read",
+		"10:00:04 task.final         engine",
+	}
+	if got := LogMatches(m.Log, "TOOL.CALL"); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("LogMatches case-insensitive = %v", got)
+	}
+
+	Update(m, typeKey(input.Escape)) // NORMAL
+	Update(m, runeKey('/'))
+	if m.Mode != modeSearch {
+		t.Fatal("'/' did not enter SEARCH mode")
+	}
+	for _, r := range "tool.call" {
+		Update(m, runeKey(r))
+	}
+	if m.Filter != "tool.call" || m.hit != 1 {
+		t.Fatalf("filter=%q hit=%d, want live filter on the latest match", m.Filter, m.hit)
+	}
+	Update(m, typeKey(input.Enter))
+	if m.Mode != modeNormal || !strings.Contains(m.Status, "2 matches") {
+		t.Fatalf("Enter should keep the filter: mode=%v status=%q", m.Mode, m.Status)
+	}
+
+	turns := len(m.Turns)
+	Update(m, runeKey('n'))
+	if m.hit != 0 {
+		t.Fatalf("n should wrap to the first match, hit=%d", m.hit)
+	}
+	if len(m.Turns) != turns || m.Input.Value != "" {
+		t.Fatal("n started a new conversation while filtering")
+	}
+	Update(m, runeKey('N'))
+	if m.hit != 1 {
+		t.Fatalf("N should wrap back to the last match, hit=%d", m.hit)
+	}
+	view := tooeytest.RenderText(m.viewLog(), 140, 8)
+	if !strings.Contains(view, "/tool.call (2/2)") || strings.Contains(view, "task.received") {
+		t.Fatalf("filtered log not rendered:\n%s", view)
+	}
+
+	Update(m, typeKey(input.Escape))
+	if m.Filter != "" {
+		t.Fatalf("Escape should clear the filter, got %q", m.Filter)
+	}
+}
+
+// NORMAL 'w' saves the run log even when the task errored: the log lines and
+// the engine's error message both land in the file.
+func TestWriteRunLogOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kyotee", "last-run.log")
+	orig := LastRunLog
+	LastRunLog = path
+	defer func() { LastRunLog = orig }()
+
+	m := NewModel(NewClient("http://localhost:0"))
+	m.reset("t1")
+	Update(m, SSEMsg{Event: events.Event{TaskID: "t1", Seq: 1, Kind: events.KindTaskReceived, Actor: "engine"}})
+	Update(m, SSEMsg{Event: events.Event{TaskID: "t1", Seq: 2, Kind: events.KindError, Actor: "solver",
+		Payload: map[string]any{"message": "anthropic: 529 overloaded"}}})
+
+	Update(m, typeKey(input.Escape))
+	res := Update(m, runeKey('w'))
+	if len(res.Cmds) == 0 {
+		t.Fatal("NORMAL 'w' should return a write command")
+	}
+	Update(m, res.Cmds[len(res.Cmds)-1]())
+	if !strings.Contains(m.Status, "run log written to "+path) {
+		t.Fatalf("no confirmation: %q", m.Status)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"task t1", "task.received", "--- error ---", "anthropic: 529 overloaded"} {
+		if !strings.Contains(string(raw), want) {
+			t.Fatalf("run log missing %q:\n%s", want, raw)
+		}
+	}
+}
+
+// The event log keeps only the newest LogLines lines, and the pane shows the
+// newest LogView of those.
+func TestLogRetentionCap(t *testing.T) {
+	m := NewModel(NewClient("http://localhost:0"))
+	m.LogLines, m.LogView = 5, 3
+	m.reset("t1")
+	for i := 1; i <= 12; i++ {
+		Update(m, SSEMsg{Event: events.Event{TaskID: "t1", Seq: int64(i), Kind: events.KindStageStart, Actor: fmt.Sprintf("a%02d", i)}})
+	}
+	if len(m.Log) != 5 || !strings.HasSuffix(m.Log[0], "a08") || !strings.HasSuffix(m.Log[4], "a12") {
+		t.Fatalf("log should hold the newest 5 lines:\n%s", strings.Join(m.Log, "\n"))
+	}
+	if cap(m.Log) > 8 {
+		t.Fatalf("backing array grew past the cap: %d", cap(m.Log))
+	}
+	frame := tooeytest.RenderText(m.viewLog(), 80, 6)
+	if strings.Contains(frame, "a09") || !strings.Contains(frame, "a10") || !strings.Contains(frame, "a12") {
+		t.Fatalf("pane should show the newest 3 lines:\n%s", frame)
+	}
+}
@@ -3,14 +3,24 @@ package tui
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/stukennedy/tooey/app"
 	"golang.org/x/term"
 )
 
+// Options are the launch-time display settings.
+type Options struct {
+	Verbose    bool          // show formatted tool inputs in the thinking pane and event log
+	StallAfter time.Duration // silence before a task is flagged as stuck (<= 0 = off)
+	Theme      string        // palette name (config tui.theme); NO_COLOR forces mono
+	LogLines   int           // event-log lines kept in memory (0 = DefaultLogLines)
+	LogView    int           // event-log lines shown (0 = DefaultLogView)
+}
+
 // Run starts the TUI against an engine at baseURL, taking the terminal into
 // raw mode for the duration.
-func Run(ctx context.Context, baseURL string) error {
+func Run(ctx context.Context, baseURL string, opts Options) error {
 	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
 	if err == nil {
 		defer term.Restore(int(os.Stdin.Fd()), oldState)
@@ -18,7 +28,20 @@ func Run(ctx context.Context, baseURL string) error {
 
 	client := NewClient(baseURL)
 	a := &app.App[*Model]{
-		Init:   func() *Model { return NewModel(client) },
+		Init: func() *Model {
+			m := NewModel(client)
+			m.Verbose = opts.Verbose
+			m.LogLines, m.LogView = opts.LogLines, opts.LogView
+			m.Theme = LoadTheme(opts.Theme)
+			if os.Getenv("NO_COLOR") != "" { // https://no-color.org
+				m.Theme = Themes["mono"]
+			}
+			m.StallAfter = opts.StallAfter
+			if m.StallAfter <= 0 {
+				m.StallAfter = -1 // Model treats 0 as "default"
+			}
+			return m
+		},
 		Update: Update,
 		View:   View,
 	}
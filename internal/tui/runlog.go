@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stukennedy/tooey/app"
+)
+
+// LastRunLog is where NORMAL 'w' saves the current task's log, relative to
+// the working directory (next to the project config overlay). `kyotee log`
+// prints it back after the alt-screen is gone.
+var LastRunLog = filepath.Join(".kyotee", "last-run.log")
+
+// RunLogWrittenMsg is the result of writing the run log.
+type RunLogWrittenMsg struct {
+	Path string
+	Err  error
+}
+
+// RunLog renders the current task as plain text: a header, the event log,
+// then the final answer — or the engine error when the task failed, so a
+// broken run is exactly as exportable as a successful one.
+func (m *Model) RunLog() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "task %s", m.TaskID)
+	if m.Strategy != "" {
+		fmt.Fprintf(&b, " · %s", m.Strategy)
+	}
+	fmt.Fprintf(&b, " · $%.4f\n", m.SpentUSD)
+	if m.lastPrompt != "" {
+		fmt.Fprintf(&b, "prompt: %s\n", m.lastPrompt)
+	} else if n := len(m.Turns); n > 0 && m.Final != "" {
+		fmt.Fprintf(&b, "prompt: %s\n", m.Turns[n-1].Prompt)
+	}
+	b.WriteString("\n")
+	for _, line := range m.Log {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	switch {
+	case m.Final != "":
+		b.WriteString("\n--- answer ---\n")
+		b.WriteString(m.Final)
+		b.WriteString("\n")
+	case m.errMsg != "":
+		b.WriteString("\n--- error ---\n")
+		b.WriteString(m.errMsg)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// WriteRunLogCmd saves text to path, creating the parent directory.
+func WriteRunLogCmd(path, text string) app.Cmd {
+	return func() app.Msg {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return RunLogWrittenMsg{Path: path, Err: err}
+		}
+		return RunLogWrittenMsg{Path: path, Err: os.WriteFile(path, []byte(text), 0o644)}
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import "github.com/stukennedy/tooey/node"
+
+// Theme is the TUI palette. Views read colours from Model.Theme, so a theme
+// is picked once at launch (config tui.theme, or mono under NO_COLOR).
+type Theme struct {
+	Accent  node.Color // headings, prompts, SEARCH mode
+	OK      node.Color // healthy cost meter, consensus reached
+	Warn    node.Color // status line, votes, NORMAL mode
+	Hot     node.Color // cost meter past 80%
+	Danger  node.Color // cost meter past 95%, errors
+	Dim     node.Color // secondary text
+	Diverge node.Color // divergent brain
+	Conv    node.Color // convergent brain, INSERT mode
+	ModalBG node.Color // modal backdrop
+}
+
+// Themes are the built-in palettes by config name.
+var Themes = map[string]Theme{
+	// ANSI-256 neon on a dark terminal; the original palette.
+	"cyberpunk": {Accent: 39, OK: 42, Warn: 220, Hot: 208, Danger: 196, Dim: 245, Diverge: 213, Conv: 117, ModalBG: 236},
+	// Darker ANSI-256 shades that stay legible on a white background.
+	"light": {Accent: 25, OK: 28, Warn: 130, Hot: 166, Danger: 160, Dim: 242, Diverge: 127, Conv: 30, ModalBG: 254},
+	// Solarized accents (truecolor), base02 backdrop.
+	"solarized": {
+		Accent: node.RGB(0x26, 0x8b, 0xd2), OK: node.RGB(0x85, 0x99, 0x00), Warn: node.RGB(0xb5, 0x89, 0x00),
+		Hot: node.RGB(0xcb, 0x4b, 0x16), Danger: node.RGB(0xdc, 0x32, 0x2f), Dim: node.RGB(0x58, 0x6e, 0x75),
+		Diverge: node.RGB(0xd3, 0x36, 0x82), Conv: node.RGB(0x2a, 0xa1, 0x98), ModalBG: node.RGB(0x07, 0x36, 0x42),
+	},
+	// Terminal defaults only: no colour is ever emitted. Meaning is still
+	// carried by text (percentages, mode names, the › search marker).
+	"mono": {},
+}
+
+// DefaultTheme is used when no theme is configured.
+const DefaultTheme = "cyberpunk"
+
+// LoadTheme returns the named palette, falling back to DefaultTheme for an
+// empty or unknown name (config validation rejects unknown names earlier).
+func LoadTheme(name string) Theme {
+	if t, ok := Themes[name]; ok {
+		return t
+	}
+	return Themes[DefaultTheme]
+}
@@ -4,24 +4,12 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/stukennedy/kyotee/internal/events"
 	"github.com/stukennedy/tooey/component"
 	"github.com/stukennedy/tooey/markdown"
 	"github.com/stukennedy/tooey/node"
 )
 
-// ANSI-256 palette.
-const (
-	cAccent  = 39  // blue
-	cOK      = 42  // green
-	cWarn    = 220 // yellow
-	cHot     = 208 // orange
-	cDanger  = 196 // red
-	cDim     = 245
-	cDiverge = 213 // pink — divergent brain
-	cConv    = 117 // cyan — convergent brain
-	cModalBG = 236 // modal backdrop
-)
-
 // View renders the model. Modals are Tooey v0.5 overlays with focus scopes:
 // they paint on top of the live main UI and trap Escape as DismissMsg.
 func View(m *Model, focused string) node.Node {
@@ -42,7 +30,7 @@ func (m *Model) viewMain() node.Node {
 		m.viewHeader(),
 		node.Row(
 			node.Box(node.BorderRounded, node.Column(
-				node.TextStyled(" Prompt ", cAccent, 0, node.Bold),
+				node.TextStyled(" Prompt ", m.Theme.Accent, 0, node.Bold),
 				m.Input.Render("> ", 0, 0, 70),
 			)).WithFlex(3),
 			node.Box(node.BorderRounded, m.viewRouting()).WithFlex(2),
@@ -51,7 +39,7 @@ func (m *Model) viewMain() node.Node {
 		node.Row(
 			node.Box(node.BorderRounded, m.viewThinking()).WithFlex(1),
 			node.Box(node.BorderRounded, m.viewLog()).WithFlex(2),
-		).WithSize(0, 9),
+		).WithSize(0, m.logView()+3), // log lines + title + border
 		m.viewFooter(),
 	)
 }
@@ -67,19 +55,19 @@ func (m *Model) viewHeader() node.Node {
 		if m.Connected {
 			label = " ● live ⇅ "
 		}
-		conn = node.TextStyled(label, cOK, 0, 0)
+		conn = node.TextStyled(label, m.Theme.OK, 0, 0)
 	case m.pollStarted:
-		conn = node.TextStyled(" ● offline ", cDanger, 0, 0)
+		conn = node.TextStyled(" ● offline ", m.Theme.Danger, 0, 0)
 	default:
-		conn = node.TextStyled(" ● connecting ", cDim, 0, 0)
+		conn = node.TextStyled(" ● connecting ", m.Theme.Dim, 0, 0)
 	}
 	idLabel := " " + m.TaskID + " "
 	if n := len(m.Turns); n > 0 {
 		idLabel = fmt.Sprintf(" %s · turns:%d ", m.TaskID, n)
 	}
 	return node.Row(
-		node.TextStyled(" Kyotee Harness ", cAccent, 0, node.Bold),
-		node.TextStyled(idLabel, cDim, 0, 0),
+		node.TextStyled(" Kyotee Harness ", m.Theme.Accent, 0, node.Bold),
+		node.TextStyled(idLabel, m.Theme.Dim, 0, 0),
 		node.Spacer(),
 		conn,
 		m.costMeter(),
@@ -90,17 +78,17 @@ func (m *Model) viewHeader() node.Node {
 // (spec 08 §3). Uses the v0.5 Progress component for the bar.
 func (m *Model) costMeter() node.Node {
 	if m.LimitUSD <= 0 {
-		return node.TextStyled(" cost: $0.00 ", cDim, 0, 0)
+		return node.TextStyled(" cost: $0.00 ", m.Theme.Dim, 0, 0)
 	}
 	pct := m.SpentUSD / m.LimitUSD
-	color := node.Color(cOK)
+	color := m.Theme.OK
 	switch {
 	case pct >= 0.95:
-		color = cDanger
+		color = m.Theme.Danger
 	case pct >= 0.80:
-		color = cHot
+		color = m.Theme.Hot
 	case pct >= 0.50:
-		color = cWarn
+		color = m.Theme.Warn
 	}
 	return node.Row(
 		node.TextStyled(fmt.Sprintf(" cost: $%.2f / $%.2f ", m.SpentUSD, m.LimitUSD), color, 0, node.Bold),
@@ -121,12 +109,16 @@ func (m *Model) viewRouting() node.Node {
 	if m.Override.Strategy != "" || m.Override.Thinking != "" || m.Override.BudgetUSD > 0 {
 		ov = fmt.Sprintf("override→ %s %s $%.0f", m.Override.Strategy, m.Override.Thinking, m.Override.BudgetUSD)
 	}
+	stage := " strategy: " + orDash(m.Strategy) + "   stage: " + orDash(m.Stage)
+	if m.Round > 0 {
+		stage += "   round: " + events.RoundProgress(m.Round, m.RoundsMax)
+	}
 	return node.Column(
-		node.TextStyled(" Routing ", cAccent, 0, node.Bold),
+		node.TextStyled(" Routing ", m.Theme.Accent, 0, node.Bold),
 		node.Text(" class: "+class),
-		node.Text(" strategy: "+orDash(m.Strategy)+"   stage: "+orDash(m.Stage)),
+		node.Text(stage),
 		node.Text(" pipeline: "+orDash(strings.Join(m.Pipeline, "→"))),
-		node.TextStyled(" "+ov, cWarn, 0, 0),
+		node.TextStyled(" "+ov, m.Theme.Warn, 0, 0),
 	)
 }
 
@@ -144,34 +136,37 @@ func (m *Model) viewCenter() node.Node {
 func (m *Model) viewSolo() node.Node {
 	var rows []node.Node
 	for _, t := range m.Turns { // running conversation transcript
-		rows = append(rows, turnBlock(t.Prompt, t.Answer)...)
+		rows = append(rows, m.turnBlock(t.Prompt, t.Answer)...)
 	}
 	switch {
 	case m.lastPrompt != "": // a turn is in flight
-		answer := node.TextStyled(" …working… ", cDim, 0, 0)
-		if m.Final != "" {
+		answer := node.TextStyled(" …working… ", m.Theme.Dim, 0, 0)
+		switch {
+		case m.Final != "":
 			answer = renderMarkdown(m.Final, 110)
+		case m.Partial != "":
+			answer = wrapText(m.Partial, 110)
 		}
 		rows = append(rows,
-			node.TextStyled(" › "+truncate(m.lastPrompt, 100), cAccent, 0, node.Bold),
+			node.TextStyled(" › "+truncate(m.lastPrompt, 100), m.Theme.Accent, 0, node.Bold),
 			answer)
 	case len(m.Turns) == 0 && m.Final != "": // e.g. a resumed one-shot task
 		rows = append(rows,
-			node.TextStyled(" Answer ", cAccent, 0, node.Bold),
+			node.TextStyled(" Answer ", m.Theme.Accent, 0, node.Bold),
 			renderMarkdown(m.Final, 110))
 	case len(rows) == 0:
 		rows = append(rows,
-			node.TextStyled(" Working ", cAccent, 0, node.Bold),
-			node.TextStyled(" …working… ", cDim, 0, 0))
+			node.TextStyled(" Working ", m.Theme.Accent, 0, node.Bold),
+			node.TextStyled(" …working… ", m.Theme.Dim, 0, 0))
 	}
 	return node.Column(rows...).WithScrollToBottom()
 }
 
 // turnBlock renders one completed conversation exchange: the prompt, then the
 // markdown-styled answer.
-func turnBlock(prompt, answer string) []node.Node {
+func (m *Model) turnBlock(prompt, answer string) []node.Node {
 	return []node.Node{
-		node.TextStyled(" › "+truncate(prompt, 100), cAccent, 0, node.Bold),
+		node.TextStyled(" › "+truncate(prompt, 100), m.Theme.Accent, 0, node.Bold),
 		renderMarkdown(answer, 110),
 		node.Text(""),
 	}
@@ -189,11 +184,11 @@ func renderMarkdown(s string, width int) node.Node {
 
 func (m *Model) viewTwoBrain() node.Node {
 	var left, right []node.Node
-	left = append(left, node.TextStyled(" divergent ", cDiverge, 0, node.Bold))
-	right = append(right, node.TextStyled(" convergent ", cConv, 0, node.Bold))
+	left = append(left, node.TextStyled(" divergent ", m.Theme.Diverge, 0, node.Bold))
+	right = append(right, node.TextStyled(" convergent ", m.Theme.Conv, 0, node.Bold))
 	for _, t := range m.Brains {
 		line := node.Column(
-			node.TextStyled(fmt.Sprintf(" round %d ", t.Round), cDim, 0, 0),
+			node.TextStyled(" round "+events.RoundProgress(t.Round, m.RoundsMax)+" ", m.Theme.Dim, 0, 0),
 			wrapText(t.Text, 55),
 		)
 		if t.Role == "divergent" {
@@ -213,7 +208,7 @@ func (m *Model) viewTwoBrain() node.Node {
 			text = m.Final
 		}
 		return node.Column(cols,
-			node.TextStyled(" referee ", cOK, 0, node.Bold),
+			node.TextStyled(" referee ", m.Theme.OK, 0, node.Bold),
 			renderMarkdown(text, 110),
 		)
 	}
@@ -222,7 +217,7 @@ func (m *Model) viewTwoBrain() node.Node {
 
 func (m *Model) viewCouncil() node.Node {
 	if len(m.Members) == 0 {
-		return node.Column(node.TextStyled(" Council convening… ", cDim, 0, 0))
+		return node.Column(node.TextStyled(" Council convening… ", m.Theme.Dim, 0, 0))
 	}
 	panes := make([]node.Node, 0, len(m.Members))
 	for _, name := range m.Members {
@@ -232,18 +227,18 @@ func (m *Model) viewCouncil() node.Node {
 			voteLine = fmt.Sprintf("vote: %s (%.2f)", mv.Choice, mv.Confidence)
 		}
 		panes = append(panes, node.Box(node.BorderSingle, node.Column(
-			node.TextStyled(" "+name+" ", cAccent, 0, node.Bold),
-			node.TextStyled(" "+voteLine, cWarn, 0, 0),
+			node.TextStyled(" "+name+" ", m.Theme.Accent, 0, node.Bold),
+			node.TextStyled(" "+voteLine, m.Theme.Warn, 0, 0),
 			wrapText(mv.Position, 36),
 		).WithScrollToBottom()).WithFlex(1))
 	}
 	rows := []node.Node{
 		node.Row(panes...).WithFlex(1),
-		node.TextStyled(" "+orDash(m.Consensus), cOK, 0, node.Bold),
+		node.TextStyled(" "+orDash(m.Consensus), m.Theme.OK, 0, node.Bold),
 	}
 	if m.Synthesis != "" {
 		rows = append(rows,
-			node.TextStyled(" synthesis ", cOK, 0, node.Bold),
+			node.TextStyled(" synthesis ", m.Theme.OK, 0, node.Bold),
 			renderMarkdown(m.Synthesis, 110))
 	}
 	return node.Column(rows...)
@@ -251,72 +246,109 @@ func (m *Model) viewCouncil() node.Node {
 
 func (m *Model) viewThinking() node.Node {
 	lines := []node.Node{
-		node.TextStyled(" Thinking ", cAccent, 0, node.Bold),
+		node.TextStyled(" Thinking ", m.Theme.Accent, 0, node.Bold),
 		node.Text(" mode: " + orDash(m.ThinkMode)),
 		node.Text(" tool-check: " + orDash(m.ToolCheck)),
 	}
 	for i, tc := range m.ToolCalls {
 		if i >= 3 {
-			lines = append(lines, node.TextStyled(fmt.Sprintf("  … %d more", len(m.ToolCalls)-3), cDim, 0, 0))
+			lines = append(lines, node.TextStyled(fmt.Sprintf("  … %d more", len(m.ToolCalls)-3), m.Theme.Dim, 0, 0))
 			break
 		}
-		lines = append(lines, node.TextStyled("  ⚒ "+tc, cDim, 0, 0))
+		lines = append(lines, node.TextStyled("  ⚒ "+tc, m.Theme.Dim, 0, 0))
 	}
 	return node.Column(lines...)
 }
 
 func (m *Model) viewLog() node.Node {
-	lines := []node.Node{node.TextStyled(" Event Log ", cAccent, 0, node.Bold)}
+	if m.Filter != "" || m.Mode == modeSearch {
+		return m.viewLogFiltered()
+	}
+	lines := []node.Node{node.TextStyled(" Event Log ", m.Theme.Accent, 0, node.Bold)}
 	logs := m.Log
-	if len(logs) > 6 {
-		logs = logs[len(logs)-6:]
+	if n := m.logView(); len(logs) > n {
+		logs = logs[len(logs)-n:]
 	}
 	for _, l := range logs {
-		lines = append(lines, node.TextStyled(" "+l, cDim, 0, 0))
+		lines = append(lines, node.TextStyled(" "+l, m.Theme.Dim, 0, 0))
 	}
 	return node.Column(lines...).WithScrollToBottom()
 }
 
+// viewLogFiltered shows only lines matching the filter, in a LogView-line
+// window that follows the selected match, which is highlighted.
+func (m *Model) viewLogFiltered() node.Node {
+	query := m.Filter
+	if m.Mode == modeSearch {
+		query = m.Search.Value + "▏"
+	}
+	matches := LogMatches(m.Log, m.Filter)
+	title := fmt.Sprintf(" Event Log /%s ", query)
+	if len(matches) > 0 && m.hit >= 0 {
+		title += fmt.Sprintf("(%d/%d) ", m.hit+1, len(matches))
+	} else if m.Filter != "" {
+		title += "(no matches) "
+	}
+	lines := []node.Node{node.TextStyled(title, m.Theme.Accent, 0, node.Bold)}
+	n := m.logView()
+	from := max(0, min(m.hit-n+1, len(matches)-n))
+	for i := from; i < len(matches) && i < from+n; i++ {
+		if i == m.hit {
+			lines = append(lines, node.TextStyled("›"+m.Log[matches[i]], m.Theme.Warn, 0, node.Bold))
+		} else {
+			lines = append(lines, node.TextStyled(" "+m.Log[matches[i]], m.Theme.Dim, 0, 0))
+		}
+	}
+	return node.Column(lines...)
+}
+
 func (m *Model) viewFooter() node.Node {
-	mode, modeColor, hint := " -- INSERT -- ", node.Color(cConv),
+	mode, modeColor, hint := " -- INSERT -- ", m.Theme.Conv,
 		" type · Enter: submit · Esc: NORMAL mode "
-	if m.Mode == modeNormal {
-		mode, modeColor, hint = " -- NORMAL -- ", node.Color(cWarn),
-			" i/a: insert · Enter: submit · n: new convo · o: override · c: config · r: resume · q: quit "
+	switch {
+	case m.Mode == modeSearch:
+		mode, modeColor, hint = " -- SEARCH -- ", m.Theme.Accent,
+			" type to filter the log · Enter: keep · Esc: clear "
+	case m.Mode == modeNormal && m.Filter != "":
+		mode, modeColor, hint = " -- NORMAL -- ", m.Theme.Warn,
+			" n/N: next/prev match · /: edit filter · Esc: clear filter · i/a: insert · q: quit "
+	case m.Mode == modeNormal:
+		mode, modeColor, hint = " -- NORMAL -- ", m.Theme.Warn,
+			" i/a: insert · Enter: submit · n: new convo · o: override · c: config · r: resume · x: cancel · /: search log · w: save log · q: quit "
 	}
 	return node.Row(
-		node.TextStyled(hint, cDim, 0, 0),
+		node.TextStyled(hint, m.Theme.Dim, 0, 0),
 		node.Spacer(),
 		node.TextStyled(mode, modeColor, 0, node.Bold),
-		node.TextStyled(" "+m.Status+" ", cWarn, 0, 0),
+		node.TextStyled(" "+m.Status+" ", m.Theme.Warn, 0, 0),
 	)
 }
 
 // modal wraps overlay content in a backdrop box with a focus scope, so
 // Escape arrives as DismissMsg and Tab stays inside while it is open.
-func modal(key string, w, h int, content node.Node) node.Node {
+func (m *Model) modal(key string, w, h int, content node.Node) node.Node {
 	return node.Box(node.BorderRounded, content).
 		WithSize(w, h).
-		WithBG(cModalBG).
+		WithBG(m.Theme.ModalBG).
 		WithKey(key).
 		WithFocusScope()
 }
 
 func (m *Model) viewConfig() node.Node {
-	return modal("modal-config", 106, 34, node.Column(
-		node.TextStyled(" Config — Enter: save & hot-reload · Shift+Enter: newline · Esc: cancel ", cAccent, cModalBG, node.Bold),
-		node.Column(m.ConfigInput.Render("", 0, cModalBG, 100)).WithFlex(1).WithScrollToBottom(),
-		node.TextStyled(" "+m.Status+" ", cWarn, cModalBG, 0),
+	return m.modal("modal-config", 106, 34, node.Column(
+		node.TextStyled(" Config — Enter: save & hot-reload · Shift+Enter: newline · Esc: cancel ", m.Theme.Accent, m.Theme.ModalBG, node.Bold),
+		node.Column(m.ConfigInput.Render("", 0, m.Theme.ModalBG, 100)).WithFlex(1).WithScrollToBottom(),
+		node.TextStyled(" "+m.Status+" ", m.Theme.Warn, m.Theme.ModalBG, 0),
 	))
 }
 
 func (m *Model) viewResume() node.Node {
 	lines := []node.Node{
-		node.TextStyled(" Resume a task — ↑/↓ · Enter: resume · Esc: cancel ", cAccent, cModalBG, node.Bold),
+		node.TextStyled(" Resume a task — ↑/↓ · Enter: resume · Esc: cancel ", m.Theme.Accent, m.Theme.ModalBG, node.Bold),
 		node.Text(""),
 	}
 	if len(m.Tasks) == 0 {
-		lines = append(lines, node.TextStyled(" no persisted tasks ", cDim, cModalBG, 0))
+		lines = append(lines, node.TextStyled(" no persisted tasks ", m.Theme.Dim, m.Theme.ModalBG, 0))
 	}
 	for i, t := range m.Tasks {
 		status := "final"
@@ -327,13 +359,13 @@ func (m *Model) viewResume() node.Node {
 		}
 		line := fmt.Sprintf(" %s  $%.2f  %-10s  %s", t.TaskID, t.SpentUSD, status, truncate(t.Original, 46))
 		if i == m.TaskSel {
-			lines = append(lines, node.TextStyled("> "+line, cAccent, cModalBG, node.Bold))
+			lines = append(lines, node.TextStyled("> "+line, m.Theme.Accent, m.Theme.ModalBG, node.Bold))
 		} else {
-			lines = append(lines, node.TextStyled("  "+line, 0, cModalBG, 0))
+			lines = append(lines, node.TextStyled("  "+line, 0, m.Theme.ModalBG, 0))
 		}
 	}
-	lines = append(lines, node.Spacer(), node.TextStyled(" "+m.Status+" ", cWarn, cModalBG, 0))
-	return modal("modal-resume", 100, 20, node.Column(lines...))
+	lines = append(lines, node.Spacer(), node.TextStyled(" "+m.Status+" ", m.Theme.Warn, m.Theme.ModalBG, 0))
+	return m.modal("modal-resume", 100, 20, node.Column(lines...))
 }
 
 func (m *Model) viewOverride() node.Node {
@@ -347,15 +379,15 @@ func (m *Model) viewOverride() node.Node {
 	if m.Override.BudgetUSD > 0 {
 		budget = fmt.Sprintf("$%.0f", m.Override.BudgetUSD)
 	}
-	return modal("modal-override", 64, 12, node.Column(
-		node.TextStyled(" Override & escalate — applies to the NEXT task ", cAccent, cModalBG, node.Bold),
+	return m.modal("modal-override", 64, 12, node.Column(
+		node.TextStyled(" Override & escalate — applies to the NEXT task ", m.Theme.Accent, m.Theme.ModalBG, node.Bold),
 		node.Text(""),
-		node.TextStyled("  s → strategy   : "+val(m.Override.Strategy), 0, cModalBG, 0),
-		node.TextStyled("  t → thinking   : "+val(m.Override.Thinking), 0, cModalBG, 0),
-		node.TextStyled("  +/- → budget   : "+budget, 0, cModalBG, 0),
-		node.TextStyled("  x → clear all overrides", 0, cModalBG, 0),
+		node.TextStyled("  s → strategy   : "+val(m.Override.Strategy), 0, m.Theme.ModalBG, 0),
+		node.TextStyled("  t → thinking   : "+val(m.Override.Thinking), 0, m.Theme.ModalBG, 0),
+		node.TextStyled("  +/- → budget   : "+budget, 0, m.Theme.ModalBG, 0),
+		node.TextStyled("  x → clear all overrides", 0, m.Theme.ModalBG, 0),
 		node.Text(""),
-		node.TextStyled(" Enter/Esc: back ", cDim, cModalBG, 0),
+		node.TextStyled(" Enter/Esc: back ", m.Theme.Dim, m.Theme.ModalBG, 0),
 	))
 }
 
@@ -60,3 +60,23 @@ func TestCostMeterThresholdColours(t *testing.T) {
 		}
 	}
 }
+
+// Themes are distinct palettes; unknown names fall back to the default, and
+// mono emits no colour at all.
+func TestLoadTheme(t *testing.T) {
+	def, light := LoadTheme(DefaultTheme), LoadTheme("light")
+	if light == def || light.Accent == def.Accent || light.ModalBG == def.ModalBG {
+		t.Fatalf("light theme should differ from the default: %+v", light)
+	}
+	if LoadTheme("nope") != def || LoadTheme("") != def {
+		t.Fatal("unknown theme should fall back to the default")
+	}
+	if (LoadTheme("mono") != Theme{}) {
+		t.Fatalf("mono must use terminal defaults only: %+v", LoadTheme("mono"))
+	}
+	m := NewModel(NewClient("http://localhost:0"))
+	m.Theme = LoadTheme("solarized")
+	if frame := tooeytest.RenderText(m.viewFooter(), 120, 1); !strings.Contains(frame, "INSERT") {
+		t.Fatalf("footer did not render under solarized:\n%s", frame)
+	}
+}
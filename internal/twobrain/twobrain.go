@@ -131,7 +131,7 @@ func (t *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 			divPrompt = fmt.Sprintf("Task:\n%s\n\nRound %d of %d. The convergent brain's latest critique:\n%s\n\nRefine the surviving options and address the critique.",
 				st.PromptBody(), r, rounds, distill(lastConv))
 		}
-		div, err := t.turn(ctx, st, emit, t.Divergent, "divergent", prompts.Divergent, divPrompt, divTemp, r)
+		div, err := t.turn(ctx, st, emit, t.Divergent, "divergent", prompts.Divergent, divPrompt, divTemp, r, rounds)
 		if err != nil {
 			return st, err
 		}
@@ -143,7 +143,7 @@ func (t *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 		convPrompt := fmt.Sprintf("Task:\n%s\n\nRound %d of %d. The divergent brain proposes:\n%s\n\nCritique each option, rank them, and %s.",
 			st.PromptBody(), r, rounds, distill(lastDiv),
 			map[bool]string{true: "converge on the strongest with your recommendation and rationale", false: "flag which need refinement"}[r == rounds])
-		conv, err := t.turn(ctx, st, emit, t.Convergent, "convergent", prompts.Convergent, convPrompt, convTemp, r)
+		conv, err := t.turn(ctx, st, emit, t.Convergent, "convergent", prompts.Convergent, convPrompt, convTemp, r, rounds)
 		if err != nil {
 			return st, err
 		}
@@ -179,15 +179,15 @@ func (t *Stage) Run(ctx context.Context, st *pipeline.State, emit events.Emitter
 	budget.CheckWarn(&st.Budget, emit)
 	emit(events.Event{
 		Kind: events.KindBrainTurn, Stage: t.ID(), Actor: t.Referee.Name(),
-		Payload: map[string]any{"role": "referee", "round": rounds, "text": st.Draft},
+		Payload: map[string]any{"role": "referee", "round": rounds, "rounds_max": rounds, "text": st.Draft},
 	})
 	return st, nil
 }
 
-func (t *Stage) turn(ctx context.Context, st *pipeline.State, emit events.Emitter, p provider.Provider, role, system, prompt string, temp float64, round int) (string, error) {
+func (t *Stage) turn(ctx context.Context, st *pipeline.State, emit events.Emitter, p provider.Provider, role, system, prompt string, temp float64, round, rounds int) (string, error) {
 	flagged := thinking.FlaggedTools(st)
 	req := provider.Request{
-		System:          system + thinking.ToolInstruction(flagged),
+		System:          system + thinking.ToolInstruction(flagged) + thinking.StyleInstruction(st),
 		Messages:        []provider.Message{provider.UserText(prompt)},
 		ReasoningEffort: thinking.SolverEffort(st),
 		Temperature:     temp,
@@ -206,7 +206,7 @@ func (t *Stage) turn(ctx context.Context, st *pipeline.State, emit events.Emitte
 	budget.CheckWarn(&st.Budget, emit)
 	emit(events.Event{
 		Kind: events.KindBrainTurn, Stage: t.ID(), Actor: p.Name(),
-		Payload: map[string]any{"role": role, "round": round, "text": text},
+		Payload: map[string]any{"role": role, "round": round, "rounds_max": rounds, "text": text},
 	})
 	return text, nil
 }
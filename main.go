@@ -6,12 +6,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -20,6 +26,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/stukennedy/kyotee/internal/config"
+	"github.com/stukennedy/kyotee/internal/logging"
 	"github.com/stukennedy/kyotee/internal/receptionist"
 	"github.com/stukennedy/kyotee/internal/server"
 	"github.com/stukennedy/kyotee/internal/state"
@@ -35,10 +42,23 @@ func main() {
 
 func rootCmd() *cobra.Command {
 	var configPath string
+	var verbose bool
+	var stallAfter time.Duration
+	var logFile string
+	var logDefault bool
+	closeLog := func() error { return nil }
 
 	root := &cobra.Command{
 		Use:   "kyotee",
 		Short: "Multi-model AI harness: route, think, debate, budget",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			closeLog, err = logging.Setup(logConsole(cmd), logTarget(logFile, logDefault))
+			return err
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return closeLog()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Default: serve the engine in-process and attach the TUI.
 			eng, cfg, err := buildEngine(configPath)
@@ -48,15 +68,17 @@ func rootCmd() *cobra.Command {
 			srv := &http.Server{Addr: cfg.Listen, Handler: eng.Handler()}
 			go func() {
 				if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-					fmt.Fprintln(os.Stderr, "engine:", err)
+					slog.Error("engine listen failed", "addr", cfg.Listen, "err", err)
 				}
 			}()
 			defer srv.Shutdown(context.Background())
 			time.Sleep(100 * time.Millisecond) // let the listener come up
-			return tui.Run(cmd.Context(), "http://"+cfg.Listen)
+			return tui.Run(cmd.Context(), "http://"+cfg.Listen, tuiOptions(cfg.TUI, verbose, stallAfter))
 		},
 	}
-	root.PersistentFlags().StringVar(&configPath, "config", "", "config file (default ~/.kyotee/config.yaml)")
+	root.PersistentFlags().StringVar(&configPath, "config", "", "config file (default ~/.kyotee/config.yaml, overlaid by ./.kyotee/config.yaml)")
+	root.PersistentFlags().StringVar(&logFile, "log-file", "", "append diagnostic logs to this file (level from $"+logging.LevelEnv+")")
+	root.PersistentFlags().BoolVar(&logDefault, "log", false, "append diagnostic logs to "+logging.DefaultFile())
 
 	serve := &cobra.Command{
 		Use:   "serve",
@@ -88,43 +110,55 @@ func rootCmd() *cobra.Command {
 		Use:   "tui",
 		Short: "Attach the TUI to a running engine",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return tui.Run(cmd.Context(), attachURL)
+			// The attached engine owns the task config; only display
+			// settings come from the local file, and a broken one falls
+			// back to the defaults rather than blocking the attach.
+			var display config.TUI
+			if cfg, err := config.Load(configPath); err == nil {
+				display = cfg.TUI
+			}
+			return tui.Run(cmd.Context(), attachURL, tuiOptions(display, verbose, stallAfter))
 		},
 	}
 	tuiCmd.Flags().StringVar(&attachURL, "url", "http://127.0.0.1:8484", "engine base URL")
+	// Display flags belong only to the commands that launch the TUI.
+	for _, c := range []*cobra.Command{root, tuiCmd} {
+		c.Flags().BoolVarP(&verbose, "verbose", "v", false, "show tool inputs, not just tool names")
+		c.Flags().DurationVar(&stallAfter, "stall-after", tui.DefaultStallAfter, "flag a task as stuck after this long with no events (0 or less disables)")
+	}
 
 	// ask is the Skill shim (spec 09): a stateless HTTP client for a running
 	// engine. --local runs an in-process engine instead (no daemon needed).
 	var strategy, thinkingMode, consensusMethod, urlFlag, threadID string
 	var maxCost float64
 	var councilRounds int
-	var doWait, jsonOut, local bool
+	var doWait, jsonOut, local, dryRun bool
 	ask := &cobra.Command{
 		Use:   "ask [prompt]",
 		Short: "Submit a task to a running engine and print the answer",
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			prompt := strings.Join(args, " ")
+			if strings.TrimSpace(prompt) == "" {
+				return server.ErrEmptyTask // before dialling or building an engine
+			}
 			ov := receptionist.Overrides{
 				Strategy: strategy, Thinking: thinkingMode, BudgetUSD: maxCost,
 				CouncilRounds: councilRounds, ConsensusMethod: consensusMethod,
 			}
 			if local {
-				// Serve the in-process engine on an ephemeral port and run
-				// the same client path, so --json/--wait/exit codes behave
-				// identically to the remote shim (spec 09 contract).
-				eng, _, err := buildEngine(configPath)
+				baseURL, stop, err := serveLocal(configPath)
 				if err != nil {
 					return err
 				}
-				ln, err := net.Listen("tcp", "127.0.0.1:0")
-				if err != nil {
-					return err
+				defer stop()
+				if dryRun {
+					return runRemoteRoute(baseURL, prompt, ov, jsonOut, os.Stdout)
 				}
-				srv := &http.Server{Handler: eng.Handler()}
-				go srv.Serve(ln)
-				defer srv.Close()
-				return runRemoteAsk("http://"+ln.Addr().String(), prompt, threadID, ov, true, jsonOut, os.Stdout, os.Stderr)
+				return runRemoteAsk(baseURL, prompt, threadID, ov, true, jsonOut, os.Stdout, os.Stderr)
+			}
+			if dryRun {
+				return runRemoteRoute(engineURL(urlFlag), prompt, ov, jsonOut, os.Stdout)
 			}
 			return runRemoteAsk(engineURL(urlFlag), prompt, threadID, ov, doWait, jsonOut, os.Stdout, os.Stderr)
 		},
@@ -137,22 +171,50 @@ func rootCmd() *cobra.Command {
 	ask.Flags().StringVar(&consensusMethod, "consensus", "", "override consensus method: vote|similarity|judge")
 	ask.Flags().BoolVar(&doWait, "wait", false, "stream progress to stderr and block until the answer; without it, print task_id and return")
 	ask.Flags().BoolVar(&jsonOut, "json", false, "print the stable JSON result contract")
+	ask.Flags().BoolVar(&dryRun, "dry-run", false, "classify and route only: print the pipeline the task would run, without solving it")
 	ask.Flags().BoolVar(&local, "local", false, "run an in-process engine instead of connecting to one")
 	ask.Flags().StringVar(&urlFlag, "url", "", "engine base URL (default $KYOTEE_URL or "+defaultEngineURL+")")
 
-	var resumeWait, resumeJSON bool
-	var resumeURL string
+	var resumeWait, resumeJSON, resumeLocal bool
+	var resumeURL, followUp string
 	resumeCmd := &cobra.Command{
 		Use:   "resume <task_id>",
-		Short: "Resume a persisted task on a running engine",
+		Short: "Resume a persisted task on a running engine, or continue it with --task",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRemoteResume(engineURL(resumeURL), args[0], resumeWait, resumeJSON, os.Stdout, os.Stderr)
+			baseURL := engineURL(resumeURL)
+			if resumeLocal {
+				// No TUI, no daemon: the in-process engine reloads the task
+				// from the state dir and continues from its checkpoints.
+				url, stop, err := serveLocal(configPath)
+				if err != nil {
+					return err
+				}
+				defer stop()
+				baseURL, resumeWait = url, true
+			}
+			if followUp != "" {
+				return runRemoteFollowUp(baseURL, args[0], followUp, resumeWait, resumeJSON, os.Stdout, os.Stderr)
+			}
+			return runRemoteResume(baseURL, args[0], resumeWait, resumeJSON, os.Stdout, os.Stderr)
 		},
 	}
+	resumeCmd.Flags().StringVar(&followUp, "task", "", "submit a follow-up task in this task's thread instead of re-running it")
 	resumeCmd.Flags().BoolVar(&resumeWait, "wait", false, "stream progress and block until the task finishes")
 	resumeCmd.Flags().BoolVar(&resumeJSON, "json", false, "print the stable JSON result contract")
 	resumeCmd.Flags().StringVar(&resumeURL, "url", "", "engine base URL")
+	resumeCmd.Flags().BoolVar(&resumeLocal, "local", false, "resume in an in-process engine instead of connecting to one (implies --wait)")
+
+	var cancelURL string
+	cancelCmd := &cobra.Command{
+		Use:   "cancel <task_id>",
+		Short: "Abort a running task (it stays resumable)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoteCancel(engineURL(cancelURL), args[0], os.Stdout)
+		},
+	}
+	cancelCmd.Flags().StringVar(&cancelURL, "url", "", "engine base URL")
 
 	var statusURL string
 	statusCmd := &cobra.Command{
@@ -165,6 +227,51 @@ func rootCmd() *cobra.Command {
 	}
 	statusCmd.Flags().StringVar(&statusURL, "url", "", "engine base URL")
 
+	var explainURL string
+	explainCmd := &cobra.Command{
+		Use:   "explain <task_id>",
+		Short: "Narrate how a task was classified, routed, and solved, from its event trail",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoteExplain(engineURL(explainURL), args[0], os.Stdout)
+		},
+	}
+	explainCmd.Flags().StringVar(&explainURL, "url", "", "engine base URL")
+
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Print the run log last saved from the TUI (w)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printRunLog(tui.LastRunLog, os.Stdout)
+		},
+	}
+
+	var cleanOlderThan string
+	var cleanDryRun bool
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove temp files left behind by crashed writes and rejected config edits; prune old tasks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			opts := cleanOptions{TempCutoff: time.Now().Add(-cleanGrace), DryRun: cleanDryRun}
+			if cleanOlderThan != "" {
+				age, err := parseAge(cleanOlderThan)
+				if err != nil {
+					return err
+				}
+				opts.TaskCutoff = time.Now().Add(-age)
+			}
+			return runClean(cfg.StateDir, os.TempDir(), opts, os.Stdout)
+		},
+	}
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "", "also prune task state and event logs last saved before this age (e.g. 7d, 36h); the newest completed task is kept")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "list what would be removed without deleting anything")
+
 	var providersURL string
 	providersCmd := &cobra.Command{
 		Use:   "providers",
@@ -175,14 +282,25 @@ func rootCmd() *cobra.Command {
 	}
 	providersCmd.Flags().StringVar(&providersURL, "url", "", "engine base URL")
 
+	var tasksURL, tasksStatus string
+	var tasksJSON bool
+	tasksCmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "List persisted tasks with their status and cost",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoteTasks(engineURL(tasksURL), tasksStatus, tasksJSON, os.Stdout)
+		},
+	}
+	tasksCmd.Flags().StringVar(&tasksStatus, "status", "", "only tasks with this status: running|completed|aborted|timed_out|incomplete")
+	tasksCmd.Flags().BoolVar(&tasksJSON, "json", false, "print the task list as a JSON array")
+	tasksCmd.Flags().StringVar(&tasksURL, "url", "", "engine base URL")
+
 	initCmd := &cobra.Command{
 		Use:   "init",
 		Short: "Write the default config to ~/.kyotee/config.yaml",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			path := configPath
-			if path == "" {
-				path = config.DefaultPath()
-			}
+			path := resolveConfigPath(configPath)
 			if _, err := os.Stat(path); err == nil {
 				return fmt.Errorf("%s already exists", path)
 			}
@@ -209,13 +327,10 @@ func rootCmd() *cobra.Command {
 		Short: "Validate a config file and exit non-zero on errors",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			path := configPath
+			path := resolveConfigPath(configPath)
 			if len(args) > 0 {
 				path = args[0]
 			}
-			if path == "" {
-				path = config.DefaultPath()
-			}
 			data, err := os.ReadFile(path)
 			if err != nil {
 				return err
@@ -232,7 +347,33 @@ func rootCmd() *cobra.Command {
 		},
 	})
 
-	root.AddCommand(serve, tuiCmd, ask, resumeCmd, statusCmd, providersCmd, initCmd, configCmd)
+	// config edit/show: edit in $EDITOR with validation before anything is
+	// saved; print the effective config (defaults applied).
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "edit",
+		Short: "Open the config in $EDITOR; save only if it validates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return editConfig(resolveConfigPath(configPath), os.Getenv("EDITOR"), os.Stdout)
+		},
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Print the effective config (defaults applied)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+			data, err := yaml.Marshal(cfg)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	})
+
+	root.AddCommand(serve, tuiCmd, ask, resumeCmd, cancelCmd, statusCmd, explainCmd, tasksCmd, logCmd, cleanCmd, providersCmd, initCmd, configCmd)
 	return root
 }
 
@@ -242,7 +383,7 @@ func buildEngine(configPath string) (*server.Engine, *config.Config, error) {
 		return nil, nil, err
 	}
 	for _, w := range cfg.Warnings() {
-		fmt.Fprintln(os.Stderr, "warning:", w)
+		slog.Warn("config", "warning", w)
 	}
 	store, err := state.NewFileStore(cfg.StateDir)
 	if err != nil {
@@ -253,7 +394,224 @@ func buildEngine(configPath string) (*server.Engine, *config.Config, error) {
 	return eng, cfg, nil
 }
 
+// serveLocal starts an in-process engine on an ephemeral port so --local
+// commands run the same client path as the remote shim, with identical
+// --json/--wait/exit-code behaviour (spec 09 contract).
+func serveLocal(configPath string) (baseURL string, stop func(), err error) {
+	eng, _, err := buildEngine(configPath)
+	if err != nil {
+		return "", nil, err
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	srv := &http.Server{Handler: eng.Handler()}
+	go srv.Serve(ln)
+	return "http://" + ln.Addr().String(), func() { srv.Close() }, nil
+}
+
 // configYAML serialises the default config for `kyotee init`.
 func configYAML() ([]byte, error) {
 	return yaml.Marshal(config.Default())
 }
+
+func resolveConfigPath(path string) string {
+	if path == "" {
+		return config.DefaultPath()
+	}
+	return path
+}
+
+// editConfig opens a scratch copy of the config at path in editor
+// ($EDITOR, falling back to vi) and saves it back only if it parses and
+// validates — a bad edit never replaces a working config. On rejection the
+// scratch copy is kept and named in the error so the edit isn't lost.
+func editConfig(path, editor string, stdout io.Writer) error {
+	original, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		original, err = configYAML()
+	}
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp("", configScratchPattern)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(original); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	cmd := exec.Command(args[0], append(args[1:], tmp.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w (edit kept in %s)", args[0], err, tmp.Name())
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if string(edited) == string(original) {
+		os.Remove(tmp.Name())
+		fmt.Fprintln(stdout, "no changes")
+		return nil
+	}
+	cfg, err := config.Parse(edited)
+	if err != nil {
+		return fmt.Errorf("%w — not saved (edit kept in %s)", err, tmp.Name())
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, edited, 0o644); err != nil {
+		return err
+	}
+	os.Remove(tmp.Name())
+	for _, w := range cfg.Warnings() {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+	fmt.Fprintln(stdout, "saved", path)
+	return nil
+}
+
+// configScratchPattern names `kyotee config edit` scratch copies in the OS
+// temp dir. A rejected edit is kept for the user; `kyotee clean` sweeps it.
+const configScratchPattern = "kyotee-config-*.yaml"
+
+// cleanGrace is how old a temp file must be before clean removes it, so a
+// sweep never races a running engine's write or an open editor.
+const cleanGrace = 10 * time.Minute
+
+// cleanOptions select what `kyotee clean` sweeps.
+type cleanOptions struct {
+	TempCutoff time.Time // scratch files modified before this are removed
+	TaskCutoff time.Time // task records saved before this are pruned; zero keeps every task
+	DryRun     bool      // list what would go, delete nothing
+}
+
+// runClean implements `kyotee clean`: it removes orphaned state-store
+// scratch files and stale config-edit copies, and with a TaskCutoff prunes
+// old task records (state plus event log, keeping the newest completed
+// task). Config and the saved TUI run log are left alone.
+func runClean(stateDir, tempDir string, opts cleanOptions, stdout io.Writer) error {
+	store, err := state.NewFileStore(stateDir)
+	if err != nil {
+		return err
+	}
+	removed, err := store.CleanTemp(opts.TempCutoff, opts.DryRun)
+	if err != nil {
+		return err
+	}
+	scratch, err := filepath.Glob(filepath.Join(tempDir, configScratchPattern))
+	if err != nil {
+		return err
+	}
+	rm, err := state.RemoveOlder(scratch, opts.TempCutoff, opts.DryRun)
+	removed = append(removed, rm...)
+	if err != nil {
+		return err
+	}
+	if !opts.TaskCutoff.IsZero() {
+		rm, err := store.PruneTasks(opts.TaskCutoff, opts.DryRun)
+		removed = append(removed, rm...)
+		if err != nil {
+			return err
+		}
+	}
+
+	var total int64
+	verb := "removed"
+	if opts.DryRun {
+		verb = "would remove"
+	}
+	for _, r := range removed {
+		fmt.Fprintln(stdout, verb, r.Path)
+		total += r.Size
+	}
+	if opts.DryRun {
+		fmt.Fprintf(stdout, "%d file(s) would be removed, %s\n", len(removed), formatBytes(total))
+	} else {
+		fmt.Fprintf(stdout, "%d file(s) removed, %s reclaimed\n", len(removed), formatBytes(total))
+	}
+	return nil
+}
+
+// parseAge reads an --older-than value: a Go duration, or whole days as "7d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q (want e.g. 7d or 36h)", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (want e.g. 7d or 36h)", s)
+	}
+	return d, nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// logConsole is where cmd's diagnostics are echoed: stderr for the headless
+// engine only. Elsewhere stderr belongs to the TUI or to the command's own
+// progress log, so records go to the log file alone (nil).
+func logConsole(cmd *cobra.Command) io.Writer {
+	if cmd.Name() == "serve" {
+		return os.Stderr
+	}
+	return nil
+}
+
+// logTarget resolves the diagnostic log file: --log-file wins, --log picks
+// the default location, neither means no file.
+func logTarget(logFile string, logDefault bool) string {
+	if logFile == "" && logDefault {
+		return logging.DefaultFile()
+	}
+	return logFile
+}
+
+// tuiOptions combines the config's display settings with the TUI flags.
+func tuiOptions(c config.TUI, verbose bool, stallAfter time.Duration) tui.Options {
+	return tui.Options{
+		Verbose:    verbose,
+		StallAfter: stallAfter,
+		Theme:      c.Theme,
+		LogLines:   c.LogLines,
+		LogView:    c.LogView,
+	}
+}
+
+// printRunLog implements `kyotee log`: copy the saved run log to stdout.
+func printRunLog(path string, stdout io.Writer) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no run log at %s (press w in the TUI to save one)", path)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(raw)
+	return err
+}
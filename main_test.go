@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stukennedy/kyotee/internal/logging"
+	"github.com/stukennedy/kyotee/internal/pipeline"
+	"github.com/stukennedy/kyotee/internal/receptionist"
+	"github.com/stukennedy/kyotee/internal/state"
+)
+
+// config edit saves a valid edit and refuses an invalid one, leaving the
+// working config untouched. The "editor" is cp from a prepared file.
+func TestEditConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir) // rejected edits keep their scratch copy
+	path := filepath.Join(dir, "config.yaml")
+	original := "version: 1\nproviders: [{name: a, vendor: mock}]\nreceptionist: {model: a, routes: [{strategy: solo, models: {primary: a}}]}\n"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	editWith := func(content string) error {
+		src := filepath.Join(dir, "edit.yaml")
+		if err := os.WriteFile(src, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return editConfig(path, "cp "+src, &bytes.Buffer{})
+	}
+
+	err := editWith(strings.Replace(original, "strategy: solo", "strategy: galactic_senate", 1))
+	if err == nil || !strings.Contains(err.Error(), "not saved") {
+		t.Fatalf("invalid edit: err=%v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Fatalf("invalid edit overwrote the config:\n%s", data)
+	}
+
+	valid := strings.Replace(original, "version: 1\n", "version: 1\ndefaults: {budget_usd: 2}\n", 1)
+	if err := editWith(valid); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != valid {
+		t.Fatalf("valid edit not saved:\n%s", data)
+	}
+}
+
+// resume --local: a second in-process engine over the same state dir (a new
+// process, in effect) picks up a task persisted by the first.
+func TestServeLocalResumesPersistedTask(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	cfg := "version: 1\nstate_dir: " + filepath.Join(dir, "tasks") +
+		"\nproviders: [{name: a, vendor: mock}]\nreceptionist: {model: a, routes: [{strategy: solo, thinking: fast, models: {primary: a}}]}\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseURL, stop, err := serveLocal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := runRemoteAsk(baseURL, "hello", "", receptionist.Overrides{}, true, true, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	stop()
+	var first askResult
+	if err := json.Unmarshal(stdout.Bytes(), &first); err != nil {
+		t.Fatal(err)
+	}
+
+	baseURL, stop, err = serveLocal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	stdout.Reset()
+	if err := runRemoteResume(baseURL, first.TaskID, true, true, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var resumed askResult
+	if err := json.Unmarshal(stdout.Bytes(), &resumed); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.TaskID != first.TaskID || resumed.Answer != first.Answer {
+		t.Fatalf("resumed %+v, want task %s answer %q", resumed, first.TaskID, first.Answer)
+	}
+}
+
+// kyotee log prints the saved file verbatim and points at w when none exists.
+func TestPrintRunLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-run.log")
+	var out bytes.Buffer
+	if err := printRunLog(path, &out); err == nil || !strings.Contains(err.Error(), "press w") {
+		t.Fatalf("missing log should explain how to save one: %v", err)
+	}
+	os.WriteFile(path, []byte("task t1\n"), 0o644)
+	if err := printRunLog(path, &out); err != nil || out.String() != "task t1\n" {
+		t.Fatalf("printRunLog = %q, %v", out.String(), err)
+	}
+}
+
+// clean sweeps stale scratch files but keeps task state, event logs, and
+// anything newer than the cutoff.
+func TestCleanRemovesStaleTempFiles(t *testing.T) {
+	stateDir, tempDir := t.TempDir(), t.TempDir()
+	write := func(path string, age time.Duration) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-age)
+		os.Chtimes(path, old, old)
+	}
+	staleTmp := filepath.Join(stateDir, ".tmp-123")
+	freshTmp := filepath.Join(stateDir, ".tmp-456")
+	taskState := filepath.Join(stateDir, "t1.json")
+	taskLog := filepath.Join(stateDir, "t1.events.ndjson")
+	staleEdit := filepath.Join(tempDir, "kyotee-config-9.yaml")
+	unrelated := filepath.Join(tempDir, "other.yaml")
+	write(staleTmp, time.Hour)
+	write(freshTmp, 0)
+	write(taskState, time.Hour)
+	write(taskLog, time.Hour)
+	write(staleEdit, time.Hour)
+	write(unrelated, time.Hour)
+
+	var out bytes.Buffer
+	if err := runClean(stateDir, tempDir, cleanOptions{TempCutoff: time.Now().Add(-cleanGrace)}, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, gone := range []string{staleTmp, staleEdit} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Fatalf("%s should have been removed", gone)
+		}
+	}
+	for _, kept := range []string{freshTmp, taskState, taskLog, unrelated} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Fatalf("%s should have been kept: %v", kept, err)
+		}
+	}
+	if !strings.Contains(out.String(), "2 file(s) removed") {
+		t.Fatalf("summary = %q", out.String())
+	}
+}
+
+func TestCleanOlderThanPrunesTasks(t *testing.T) {
+	stateDir := t.TempDir()
+	store, err := state.NewFileStore(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func(id, final string, age time.Duration) {
+		t.Helper()
+		st := pipeline.NewState(id, "task "+id)
+		st.Final = final
+		if err := store.Save(st); err != nil {
+			t.Fatal(err)
+		}
+		logPath := filepath.Join(stateDir, id+".events.ndjson")
+		os.WriteFile(logPath, []byte("{}\n"), 0o644)
+		old := time.Now().Add(-age)
+		os.Chtimes(filepath.Join(stateDir, id+".json"), old, old)
+	}
+	day := 24 * time.Hour
+	save("old-done", "answer", 30*day)
+	save("last-done", "answer", 10*day) // newest completed: always kept
+	save("old-failed", "", 9*day)
+	save("fresh", "", 0)
+
+	age, err := parseAge("7d")
+	if err != nil || age != 7*day {
+		t.Fatalf("parseAge(7d) = %v, %v", age, err)
+	}
+	opts := cleanOptions{TaskCutoff: time.Now().Add(-age), DryRun: true}
+
+	var out bytes.Buffer
+	if err := runClean(stateDir, t.TempDir(), opts, &out); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := store.List(); len(ids) != 4 {
+		t.Fatalf("dry run deleted tasks: %v", ids)
+	}
+	if !strings.Contains(out.String(), "would remove "+filepath.Join(stateDir, "old-done.json")) ||
+		!strings.Contains(out.String(), "4 file(s) would be removed") {
+		t.Fatalf("dry-run output = %q", out.String())
+	}
+
+	out.Reset()
+	opts.DryRun = false
+	if err := runClean(stateDir, t.TempDir(), opts, &out); err != nil {
+		t.Fatal(err)
+	}
+	ids, _ := store.List()
+	if strings.Join(ids, ",") != "fresh,last-done" {
+		t.Fatalf("tasks left = %v, want fresh,last-done", ids)
+	}
+	for _, gone := range []string{"old-done.events.ndjson", "old-failed.events.ndjson"} {
+		if _, err := os.Stat(filepath.Join(stateDir, gone)); !os.IsNotExist(err) {
+			t.Fatalf("%s should go with its task", gone)
+		}
+	}
+	if !strings.Contains(out.String(), "4 file(s) removed") || !strings.Contains(out.String(), "reclaimed") {
+		t.Fatalf("summary = %q", out.String())
+	}
+}
+
+// --log-file takes its value as the next argument like any string flag; it
+// must not swallow a bare --log-file and leave the path as a stray arg.
+func TestLogFileFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.log")
+	root := rootCmd()
+	if err := root.ParseFlags([]string{"--log-file", path, "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := root.Flags().GetString("log-file")
+	if got != path {
+		t.Fatalf("--log-file = %q, want %q", got, path)
+	}
+	if args := root.Flags().Args(); len(args) != 1 || args[0] != "hello" {
+		t.Fatalf("positional args = %v, want [hello]", args)
+	}
+
+	if got := logTarget("", true); got != logging.DefaultFile() {
+		t.Fatalf("--log = %q, want the default file", got)
+	}
+	if got := logTarget(path, true); got != path {
+		t.Fatalf("--log-file should win over --log, got %q", got)
+	}
+	if got := logTarget("", false); got != "" {
+		t.Fatalf("no flags should mean no log file, got %q", got)
+	}
+}
+
+// Only serve echoes diagnostics to stderr; ask/resume/tasks keep it for
+// their progress log, and the TUI owns the terminal.
+func TestLogConsoleOnlyForServe(t *testing.T) {
+	root := rootCmd()
+	for _, path := range [][]string{{}, {"tui"}, {"ask"}, {"resume"}, {"tasks"}} {
+		cmd, _, err := root.Find(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if logConsole(cmd) != nil {
+			t.Fatalf("%q logs to the console", cmd.CommandPath())
+		}
+	}
+	serve, _, _ := root.Find([]string{"serve"})
+	if logConsole(serve) != os.Stderr {
+		t.Fatal("serve should log to stderr")
+	}
+}
+
+// -v and --stall-after only mean something to the TUI, so only the commands
+// that launch it accept them.
+func TestDisplayFlagsOnlyOnTUICommands(t *testing.T) {
+	root := rootCmd()
+	for _, path := range [][]string{{}, {"tui"}} {
+		cmd, _, err := root.Find(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"verbose", "stall-after"} {
+			if cmd.Flags().Lookup(name) == nil {
+				t.Fatalf("%q lacks --%s", cmd.CommandPath(), name)
+			}
+		}
+	}
+	ask, _, err := root.Find([]string{"ask"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arg := range []string{"-v", "--stall-after=1m"} {
+		if err := ask.ParseFlags([]string{arg}); err == nil {
+			t.Fatalf("ask accepted %s", arg)
+		}
+	}
+}
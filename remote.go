@@ -11,14 +11,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/stukennedy/kyotee/internal/events"
 	"github.com/stukennedy/kyotee/internal/pipeline"
 	"github.com/stukennedy/kyotee/internal/receptionist"
+	"github.com/stukennedy/kyotee/internal/server"
 )
 
 const defaultEngineURL = "http://127.0.0.1:8484"
@@ -110,6 +114,36 @@ func (c *remoteClient) resume(taskID string) error {
 	return nil
 }
 
+// route asks the engine how a task would be routed, without running it.
+func (c *remoteClient) route(text string, ov receptionist.Overrides) (*server.RoutePlan, error) {
+	payload, _ := json.Marshal(map[string]any{"text": text, "overrides": ov})
+	resp, err := c.http.Post(c.baseURL+"/v1/route", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, errNoEngine(c.baseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiErrFrom(resp)
+	}
+	var plan server.RoutePlan
+	if err := json.NewDecoder(resp.Body).Decode(&plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (c *remoteClient) cancel(taskID string) error {
+	resp, err := c.http.Post(c.baseURL+"/v1/tasks/"+taskID+"/cancel", "application/json", nil)
+	if err != nil {
+		return errNoEngine(c.baseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return apiErrFrom(resp)
+	}
+	return nil
+}
+
 // askResult is the stable --json contract (spec 09 §4).
 type askResult struct {
 	TaskID       string        `json:"task_id"`
@@ -151,26 +185,14 @@ func (c *remoteClient) wait(taskID string, progress io.Writer) (*askResult, erro
 	res := &askResult{TaskID: taskID}
 	var terminalErr error
 	finalReason := ""
+	text := deltaLines{}
 
-	sc := bufio.NewScanner(resp.Body)
-	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
-	current := ""
-	for sc.Scan() {
-		line := sc.Text()
-		if strings.HasPrefix(line, "event: ") {
-			current = strings.TrimPrefix(line, "event: ")
-			if current == "done" {
-				break
-			}
-			continue
-		}
-		if !strings.HasPrefix(line, "data: ") || current == "done" {
-			continue
-		}
-		var ev events.Event
-		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
-			continue
+	err = scanEvents(resp.Body, func(ev events.Event) {
+		if ev.Kind == events.KindModelDelta {
+			text.add(progress, ev)
+			return
 		}
+		text.flush(progress)
 		c.progressLine(progress, ev)
 
 		p := ev.Payload
@@ -203,14 +225,14 @@ func (c *remoteClient) wait(taskID string, progress io.Writer) (*askResult, erro
 				terminalErr = fmt.Errorf("engine: %s", msg)
 			}
 		}
-	}
-	if err := sc.Err(); err != nil {
+	})
+	if err != nil {
 		return res, fmt.Errorf("event stream interrupted: %w", err)
 	}
 	if terminalErr != nil {
 		return res, terminalErr
 	}
-	if finalReason == "budget_exhausted" && strings.TrimSpace(res.Answer) == "" {
+	if (finalReason == "budget_exhausted" || finalReason == "token_budget_exhausted") && strings.TrimSpace(res.Answer) == "" {
 		return res, errBudgetNoAnswer
 	}
 
@@ -235,6 +257,29 @@ func (c *remoteClient) wait(taskID string, progress io.Writer) (*askResult, erro
 	return res, nil
 }
 
+// scanEvents reads an engine SSE stream, calling fn for each event until the
+// "done" terminator or EOF.
+func scanEvents(r io.Reader, fn func(events.Event)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "event: done" {
+			return nil
+		}
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var ev events.Event
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			continue
+		}
+		fn(ev)
+	}
+	return sc.Err()
+}
+
 // progressLine writes the terse stderr progress log (stdout stays clean for
 // the answer).
 func (c *remoteClient) progressLine(w io.Writer, ev events.Event) {
@@ -254,21 +299,67 @@ func (c *remoteClient) progressLine(w io.Writer, ev events.Event) {
 		fmt.Fprintf(w, "· tool-check  %v %v\n", p["verdict"], p["tools"])
 	case events.KindToolCall:
 		fmt.Fprintf(w, "· tool        %v %v\n", p["name"], p["input"])
+	case events.KindBrainTurn:
+		if p["role"] != "referee" {
+			fmt.Fprintf(w, "· twobrain    %v round %s\n", p["role"], events.RoundProgress(int(num(p["round"])), int(num(p["rounds_max"]))))
+		}
+	case events.KindCouncilRebuttal:
+		fmt.Fprintf(w, "· rebuttal    %v round %s\n", p["model"], events.RoundProgress(int(num(p["round"])), int(num(p["rounds_max"]))))
 	case events.KindCouncilVote:
 		fmt.Fprintf(w, "· vote        %v → %v (%.2f)\n", p["model"], p["choice"], num(p["confidence"]))
 	case events.KindCouncilConsensus:
-		fmt.Fprintf(w, "· consensus   reached=%v method=%v rounds=%v\n", p["reached"], p["method"], p["rounds_used"])
+		fmt.Fprintf(w, "· consensus   reached=%v method=%v rounds=%s\n", p["reached"], p["method"],
+			events.RoundProgress(int(num(p["rounds_used"])), int(num(p["rounds_max"]))))
 	case events.KindBudgetWarn:
 		if reason, ok := p["reason"].(string); ok {
 			fmt.Fprintf(w, "! budget      %s\n", reason)
 		} else {
 			fmt.Fprintf(w, "! budget      %.0f%% of $%.2f\n", num(p["pct"])*100, num(p["limit_usd"]))
 		}
+	case events.KindConfigChanged:
+		fmt.Fprintf(w, "! config      %v (%v → %v)\n", p["message"], p["previous"], p["current"])
 	case events.KindError:
 		fmt.Fprintf(w, "! error       %v\n", p["message"])
 	}
 }
 
+// deltaLines turns streamed model.delta text into whole "· text" progress
+// lines, buffered per model so parallel council members don't interleave
+// mid-line.
+type deltaLines map[string]*strings.Builder
+
+func (d deltaLines) add(w io.Writer, ev events.Event) {
+	chunk, _ := ev.Payload["text"].(string)
+	b := d[ev.Actor]
+	if b == nil {
+		b = &strings.Builder{}
+		d[ev.Actor] = b
+	}
+	b.WriteString(chunk)
+	rest := b.String()
+	for {
+		line, tail, ok := strings.Cut(rest, "\n")
+		if !ok {
+			break
+		}
+		fmt.Fprintf(w, "· text        %s: %s\n", ev.Actor, line)
+		rest = tail
+	}
+	b.Reset()
+	b.WriteString(rest)
+}
+
+// flush writes any unterminated text, so it lands before the next event's
+// progress line.
+func (d deltaLines) flush(w io.Writer) {
+	for _, actor := range slices.Sorted(maps.Keys(d)) {
+		if b := d[actor]; b.Len() > 0 {
+			fmt.Fprintf(w, "· text        %s: %s\n", actor, b.String())
+		}
+		delete(d, actor)
+	}
+}
+
 func num(v any) float64 {
 	f, _ := v.(float64)
 	return f
@@ -290,6 +381,35 @@ func runRemoteAsk(baseURL, prompt, threadID string, ov receptionist.Overrides, d
 	return waitAndPrint(client, taskID, jsonOut, stdout, stderr)
 }
 
+// runRemoteRoute implements `kyotee ask --dry-run`: print the class, route,
+// pipeline, models, and budget the task would get, without solving it.
+func runRemoteRoute(baseURL, prompt string, ov receptionist.Overrides, jsonOut bool, stdout io.Writer) error {
+	plan, err := newRemoteClient(baseURL).route(prompt, ov)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	}
+	models := make([]string, 0, len(plan.Models))
+	for role, name := range plan.Models {
+		models = append(models, fmt.Sprintf("%s=%v", role, name))
+	}
+	sort.Strings(models)
+	fmt.Fprintf(stdout, "class      %s/%s tools:%s (confidence %.2f)\n",
+		plan.Class.Domain, plan.Class.Complexity, plan.Class.ToolNeed, plan.Class.Confidence)
+	fmt.Fprintf(stdout, "strategy   %s (thinking: %s)\n", plan.Strategy, plan.Thinking)
+	fmt.Fprintf(stdout, "pipeline   %s\n", strings.Join(plan.Pipeline, " → "))
+	fmt.Fprintf(stdout, "models     %s\n", strings.Join(models, " "))
+	fmt.Fprintf(stdout, "budget     $%.2f\n", plan.LimitUSD)
+	for _, n := range plan.Notes {
+		fmt.Fprintf(stdout, "note       %s\n", n)
+	}
+	return nil
+}
+
 // runRemoteResume implements `kyotee resume <task_id>`.
 func runRemoteResume(baseURL, taskID string, doWait, jsonOut bool, stdout, stderr io.Writer) error {
 	client := newRemoteClient(baseURL)
@@ -303,6 +423,33 @@ func runRemoteResume(baseURL, taskID string, doWait, jsonOut bool, stdout, stder
 	return waitAndPrint(client, taskID, jsonOut, stdout, stderr)
 }
 
+// runRemoteFollowUp implements `kyotee resume <task_id> --task "..."`: the
+// new text is submitted into the task's thread, so it is answered with the
+// thread's prior turns as context and linked to the thread's latest task
+// (State.ParentID). The original task is not re-run.
+func runRemoteFollowUp(baseURL, taskID, text string, doWait, jsonOut bool, stdout, stderr io.Writer) error {
+	var st struct {
+		ThreadID string `json:"thread_id"`
+	}
+	if err := newRemoteClient(baseURL).getJSON("/v1/tasks/"+taskID, &st); err != nil {
+		return err
+	}
+	thread := st.ThreadID
+	if thread == "" {
+		thread = taskID // tasks persisted before threads existed
+	}
+	return runRemoteAsk(baseURL, text, thread, receptionist.Overrides{}, doWait, jsonOut, stdout, stderr)
+}
+
+// runRemoteCancel implements `kyotee cancel <task_id>`.
+func runRemoteCancel(baseURL, taskID string, stdout io.Writer) error {
+	if err := newRemoteClient(baseURL).cancel(taskID); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, taskID, "cancelling")
+	return nil
+}
+
 func waitAndPrint(client *remoteClient, taskID string, jsonOut bool, stdout, stderr io.Writer) error {
 	res, err := client.wait(taskID, stderr)
 	if err != nil {
@@ -343,6 +490,43 @@ func runRemoteStatus(baseURL, taskID string, stdout io.Writer) error {
 	return nil
 }
 
+// taskStatuses are the values TaskInfo.Status can take.
+var taskStatuses = []string{"running", "completed", "aborted", "timed_out", "incomplete"}
+
+// runRemoteTasks implements `kyotee tasks`: persisted tasks, optionally
+// filtered by status. With --json the filtered []TaskInfo is the only thing
+// written to stdout.
+func runRemoteTasks(baseURL, status string, jsonOut bool, stdout io.Writer) error {
+	if status != "" && !slices.Contains(taskStatuses, status) {
+		return fmt.Errorf("unknown status %q (%s)", status, strings.Join(taskStatuses, "|"))
+	}
+	var all []server.TaskInfo
+	if err := newRemoteClient(baseURL).getJSON("/v1/tasks", &all); err != nil {
+		return err
+	}
+	tasks := []server.TaskInfo{} // encode as [] rather than null when empty
+	for _, t := range all {
+		if status == "" || t.Status == status {
+			tasks = append(tasks, t)
+		}
+	}
+	if jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tasks)
+	}
+	tw := bufio.NewWriter(stdout)
+	fmt.Fprintf(tw, "%-28s %-10s %-8s %s\n", "TASK", "STATUS", "COST", "PROMPT")
+	for _, t := range tasks {
+		prompt := strings.Join(strings.Fields(t.Original), " ")
+		if r := []rune(prompt); len(r) > 60 {
+			prompt = string(r[:59]) + "…"
+		}
+		fmt.Fprintf(tw, "%-28s %-10s $%-7.4f %s\n", t.TaskID, t.Status, t.SpentUSD, prompt)
+	}
+	return tw.Flush()
+}
+
 // runRemoteProviders implements `kyotee providers`.
 func runRemoteProviders(baseURL string, stdout io.Writer) error {
 	client := newRemoteClient(baseURL)
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/stukennedy/kyotee/internal/config"
+	"github.com/stukennedy/kyotee/internal/events"
+	"github.com/stukennedy/kyotee/internal/pipeline"
 	"github.com/stukennedy/kyotee/internal/receptionist"
 	"github.com/stukennedy/kyotee/internal/server"
 	"github.com/stukennedy/kyotee/internal/state"
@@ -55,13 +57,31 @@ func TestRemoteAskWait(t *testing.T) {
 	if strings.TrimSpace(stdout.String()) != "(fake response)" {
 		t.Fatalf("stdout should carry only the answer: %q", stdout.String())
 	}
-	for _, want := range []string{"· classified", "· routed", "· stage"} {
+	for _, want := range []string{"· classified", "· routed", "· stage", "· text        a: (fake response)\n"} {
 		if !strings.Contains(stderr.String(), want) {
 			t.Fatalf("stderr progress missing %q:\n%s", want, stderr.String())
 		}
 	}
 }
 
+// Streamed chunks are joined into whole lines per model before printing.
+func TestDeltaLinesJoinChunks(t *testing.T) {
+	var w bytes.Buffer
+	d := deltaLines{}
+	for _, ev := range []events.Event{
+		{Actor: "a", Payload: map[string]any{"text": "first li"}},
+		{Actor: "b", Payload: map[string]any{"text": "other"}},
+		{Actor: "a", Payload: map[string]any{"text": "ne\nsecond"}},
+	} {
+		d.add(&w, ev)
+	}
+	d.flush(&w)
+	want := "· text        a: first line\n· text        a: second\n· text        b: other\n"
+	if w.String() != want {
+		t.Fatalf("progress =\n%s\nwant\n%s", w.String(), want)
+	}
+}
+
 // Without --wait: task_id printed, returns immediately.
 func TestRemoteAskNoWaitPrintsTaskID(t *testing.T) {
 	eng, srv := mockEngineServer(t)
@@ -77,7 +97,9 @@ func TestRemoteAskNoWaitPrintsTaskID(t *testing.T) {
 	// The task actually runs in the background.
 	deadline := time.After(5 * time.Second)
 	for {
-		if st, err := eng.Store.Load(taskID); err == nil && st.Final != "" {
+		// Wait for the run to wind down too, so its last writes land
+		// before the temp state dir is removed.
+		if st, err := eng.Store.Load(taskID); err == nil && st.Final != "" && !eng.Running(taskID) {
 			break
 		}
 		select {
@@ -182,3 +204,96 @@ func TestEngineURLResolution(t *testing.T) {
 		t.Fatalf("flag wins: %q", got)
 	}
 }
+
+// ask --dry-run reports the route the overrides produce and never starts a task.
+func TestRemoteAskDryRun(t *testing.T) {
+	eng, srv := mockEngineServer(t)
+	var stdout bytes.Buffer
+
+	ov := receptionist.Overrides{Strategy: "council", BudgetUSD: 50}
+	if err := runRemoteRoute(srv.URL, "pick a database", ov, false, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"strategy   council", "pipeline   ", "council", "budget     $50.00"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("dry-run output missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if tasks, _ := eng.Tasks(); len(tasks) != 0 {
+		t.Fatalf("dry run started a task: %+v", tasks)
+	}
+}
+
+// resume --task: the follow-up joins the original task's thread, carries it
+// as history, and links back to it as parent.
+func TestRemoteResumeFollowUp(t *testing.T) {
+	eng, srv := mockEngineServer(t)
+	taskID := func(out *bytes.Buffer) string {
+		var res askResult
+		if err := json.Unmarshal(out.Bytes(), &res); err != nil {
+			t.Fatalf("bad --json output: %v\n%s", err, out.String())
+		}
+		return res.TaskID
+	}
+	var stdout, stderr bytes.Buffer
+	if err := runRemoteAsk(srv.URL, "first question", "", receptionist.Overrides{}, true, true, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	firstID := taskID(&stdout)
+
+	stdout.Reset()
+	if err := runRemoteFollowUp(srv.URL, firstID, "now add feature X", true, true, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	st, err := eng.Store.Load(taskID(&stdout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.ThreadID != firstID || st.ParentID != firstID {
+		t.Fatalf("follow-up not linked: thread=%q parent=%q, want %q", st.ThreadID, st.ParentID, firstID)
+	}
+	if len(st.History) != 1 || st.History[0].User != "first question" {
+		t.Fatalf("prior turn not carried forward: %+v", st.History)
+	}
+}
+
+// tasks --status filters by lifecycle status; --json emits only the array.
+func TestRemoteTasksStatusFilter(t *testing.T) {
+	eng, srv := mockEngineServer(t)
+	var stdout, stderr bytes.Buffer
+	if err := runRemoteAsk(srv.URL, "finished job", "", receptionist.Overrides{}, true, false, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	// A task that stopped before answering (error or engine restart).
+	if err := eng.Store.Save(pipeline.NewState("20260101T000000-stalled", "stalled job")); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func(status string) []server.TaskInfo {
+		t.Helper()
+		var out bytes.Buffer
+		if err := runRemoteTasks(srv.URL, status, true, &out); err != nil {
+			t.Fatal(err)
+		}
+		var tasks []server.TaskInfo
+		if err := json.Unmarshal(out.Bytes(), &tasks); err != nil {
+			t.Fatalf("stdout is not a bare JSON array: %v\n%s", err, out.String())
+		}
+		return tasks
+	}
+	if got := list(""); len(got) != 2 {
+		t.Fatalf("unfiltered: %d tasks, want 2", len(got))
+	}
+	if got := list("completed"); len(got) != 1 || got[0].Original != "finished job" {
+		t.Fatalf("completed filter: %+v", got)
+	}
+	if got := list("incomplete"); len(got) != 1 || got[0].TaskID != "20260101T000000-stalled" {
+		t.Fatalf("incomplete filter: %+v", got)
+	}
+	if got := list("aborted"); len(got) != 0 {
+		t.Fatalf("aborted filter: %+v", got)
+	}
+	if err := runRemoteTasks(srv.URL, "paused", false, &stdout); err == nil || !strings.Contains(err.Error(), "unknown status") {
+		t.Fatalf("unknown status should be rejected, got %v", err)
+	}
+}